@@ -0,0 +1,293 @@
+package backup
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	Format       = "backendtest-export"
+	Version      = 2
+	ManifestName = "manifest.json"
+	BlobDir      = "blobs/"
+)
+
+// Manifest is written last, once the checksum of every other file in the
+// archive is known. An archive without it was cut off while exporting.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+type File struct {
+	Name    string `json:"name"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records,omitempty"`
+}
+
+func TableFile(table string) string {
+	return table + ".jsonl"
+}
+
+func BlobFile(key string) string {
+	return BlobDir + key
+}
+
+// Writer streams an export as a zip archive. Files are written one after
+// another; starting a file finishes the previous one.
+type Writer struct {
+	zip      *zip.Writer
+	manifest Manifest
+	current  *fileWriter
+}
+
+type fileWriter struct {
+	io.Writer
+	file    File
+	hash    hash.Hash
+	encoder *json.Encoder
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.hash.Write(p[:n])
+	w.file.Size += int64(n)
+	return n, err
+}
+
+func NewWriter(w io.Writer, createdAt time.Time) *Writer {
+	return &Writer{
+		zip:      zip.NewWriter(w),
+		manifest: Manifest{Format: Format, Version: Version, CreatedAt: createdAt.UTC(), Files: []File{}},
+	}
+}
+
+// Encode appends one record to the JSONL file of the table, starting the
+// file if the previous record belonged to another one. All records of a
+// table have to be written in one go.
+func (w *Writer) Encode(table string, record interface{}) error {
+	if err := w.Table(table); err != nil {
+		return err
+	}
+
+	if w.current.file.Name != TableFile(table) {
+		return errors.New("the records of table " + table + " are not contiguous")
+	}
+
+	w.current.file.Records++
+	return w.current.encoder.Encode(record)
+}
+
+// Table starts the JSONL file of the table even if it has no records, so
+// that restoring can tell an empty table from a missing one. Calling it for
+// a table that was already written does nothing.
+func (w *Writer) Table(table string) error {
+	name := TableFile(table)
+	if w.current != nil && w.current.file.Name == name {
+		return nil
+	}
+
+	for _, file := range w.manifest.Files {
+		if file.Name == name {
+			return nil
+		}
+	}
+
+	if err := w.create(name, zip.Deflate); err != nil {
+		return err
+	}
+	w.current.encoder = json.NewEncoder(w.current)
+	return nil
+}
+
+// Blob copies a blob into the archive. Blobs are already compressed images
+// and documents, so they are stored as they are.
+func (w *Writer) Blob(key string, r io.Reader) error {
+	if err := w.create(BlobFile(key), zip.Store); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(w.current, r)
+	return err
+}
+
+func (w *Writer) create(name string, method uint16) error {
+	w.finish()
+
+	header := &zip.FileHeader{Name: name, Method: method, Modified: w.manifest.CreatedAt}
+	entry, err := w.zip.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	w.current = &fileWriter{Writer: entry, file: File{Name: name}, hash: sha256.New()}
+	return nil
+}
+
+func (w *Writer) finish() {
+	if w.current != nil {
+		w.current.file.SHA256 = hex.EncodeToString(w.current.hash.Sum(nil))
+		w.manifest.Files = append(w.manifest.Files, w.current.file)
+		w.current = nil
+	}
+}
+
+// Close writes the manifest and the zip directory.
+func (w *Writer) Close() error {
+	w.finish()
+
+	entry, err := w.zip.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: w.manifest.CreatedAt})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(w.manifest); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
+
+// Reader gives access to the files of an export whose manifest and
+// checksums have been verified.
+type Reader struct {
+	Manifest Manifest
+	files    map[string]*zip.File
+}
+
+// OpenReader reads the whole archive once to compare every file against the
+// manifest. Files that are not listed in the manifest are rejected.
+func OpenReader(r io.ReaderAt, size int64) (*Reader, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("not an export archive: " + err.Error())
+	}
+
+	reader := &Reader{files: map[string]*zip.File{}}
+	for _, file := range archive.File {
+		reader.files[file.Name] = file
+	}
+
+	manifestFile, ok := reader.files[ManifestName]
+	if !ok {
+		return nil, errors.New("the archive has no manifest, the export is incomplete")
+	}
+
+	manifestReader, err := manifestFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	err = json.NewDecoder(manifestReader).Decode(&reader.Manifest)
+	manifestReader.Close()
+	if err != nil {
+		return nil, errors.New("invalid manifest: " + err.Error())
+	}
+
+	if reader.Manifest.Format != Format {
+		return nil, fmt.Errorf("unknown archive format %q", reader.Manifest.Format)
+	}
+
+	if reader.Manifest.Version < 1 || reader.Manifest.Version > Version {
+		return nil, fmt.Errorf("export version %d is not supported, expected at most %d", reader.Manifest.Version, Version)
+	}
+
+	listed := map[string]bool{ManifestName: true}
+	for _, file := range reader.Manifest.Files {
+		listed[file.Name] = true
+		if err := reader.verify(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var unlisted []string
+	for name := range reader.files {
+		if !listed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+	if len(unlisted) > 0 {
+		sort.Strings(unlisted)
+		return nil, errors.New("files missing from the manifest: " + strings.Join(unlisted, ", "))
+	}
+
+	return reader, nil
+}
+
+func (r *Reader) verify(file File) error {
+	content, err := r.Open(file.Name)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(sum, content)
+	if err != nil {
+		return fmt.Errorf("%s: %v", file.Name, err)
+	}
+
+	if size != file.Size || hex.EncodeToString(sum.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("%s: checksum mismatch", file.Name)
+	}
+	return nil
+}
+
+// Open returns the content of a file, or an error if the archive has no
+// such file.
+func (r *Reader) Open(name string) (io.ReadCloser, error) {
+	file, ok := r.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: not in the archive", name)
+	}
+	return file.Open()
+}
+
+// Has reports whether the manifest lists the file.
+func (r *Reader) Has(name string) bool {
+	for _, file := range r.Manifest.Files {
+		if file.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Records decodes the JSONL file of a table, calling fn with each record.
+// newRecord returns the value each line is decoded into.
+func (r *Reader) Records(table string, newRecord func() interface{}, fn func(record interface{}) error) error {
+	content, err := r.Open(TableFile(table))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	decoder := json.NewDecoder(content)
+	decoder.DisallowUnknownFields()
+	for line := 1; decoder.More(); line++ {
+		record := newRecord()
+		if err := decoder.Decode(record); err != nil {
+			return fmt.Errorf("%s: record %d: %v", TableFile(table), line, err)
+		}
+		if err := fn(record); err != nil {
+			return fmt.Errorf("%s: record %d: %v", TableFile(table), line, err)
+		}
+	}
+
+	_, err = io.Copy(ioutil.Discard, content)
+	return err
+}
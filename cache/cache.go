@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Cache stores encoded values by key. Implementations must be safe for
+// concurrent use; a shared cache such as Redis or Memcached can back the
+// repositories by implementing this interface.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+
+	Set(key string, value []byte)
+
+	Delete(keys ...string)
+}
+
+type Stats struct {
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Invalidations uint64  `json:"invalidations"`
+	HitRatio      float64 `json:"hit_ratio"`
+	Entries       *int    `json:"entries,omitempty"`
+	Evictions     *uint64 `json:"evictions,omitempty"`
+}
+
+type Counters struct {
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+func (c *Counters) Hit() {
+	atomic.AddUint64(&c.hits, 1)
+}
+
+func (c *Counters) Miss() {
+	atomic.AddUint64(&c.misses, 1)
+}
+
+func (c *Counters) Invalidate() {
+	atomic.AddUint64(&c.invalidations, 1)
+}
+
+func (c *Counters) Stats() Stats {
+	stats := Stats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Invalidations: atomic.LoadUint64(&c.invalidations),
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	return stats
+}
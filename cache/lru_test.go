@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	lru := NewLRU(2, 0)
+	lru.Set("a", []byte("1"))
+	lru.Set("b", []byte("2"))
+
+	// Reading a makes b the least recently used entry.
+	if _, ok := lru.Get("a"); !ok {
+		t.Fatal("a is missing")
+	}
+	lru.Set("c", []byte("3"))
+
+	if _, ok := lru.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := lru.Get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+	if lru.Len() != 2 || lru.Evictions() != 1 {
+		t.Errorf("len %d, evictions %d, want 2 and 1", lru.Len(), lru.Evictions())
+	}
+}
+
+func TestLRUSetReplacesWithoutEvicting(t *testing.T) {
+	lru := NewLRU(2, 0)
+	lru.Set("a", []byte("1"))
+	lru.Set("b", []byte("2"))
+	lru.Set("a", []byte("3"))
+
+	if value, _ := lru.Get("a"); string(value) != "3" {
+		t.Errorf("a = %q, want 3", value)
+	}
+	if lru.Len() != 2 || lru.Evictions() != 0 {
+		t.Errorf("len %d, evictions %d, want 2 and 0", lru.Len(), lru.Evictions())
+	}
+
+	// Replacing a made it the most recently used entry.
+	lru.Set("c", []byte("4"))
+	if _, ok := lru.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	lru := NewLRU(10, 20*time.Millisecond)
+	lru.Set("a", []byte("1"))
+	if _, ok := lru.Get("a"); !ok {
+		t.Fatal("a is missing before it expired")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	lru.Set("b", []byte("2"))
+
+	if _, ok := lru.Get("a"); ok {
+		t.Error("a did not expire")
+	}
+	if _, ok := lru.Get("b"); !ok {
+		t.Error("b expired early")
+	}
+	if lru.Len() != 1 {
+		t.Errorf("len %d, want the expired entry dropped", lru.Len())
+	}
+}
+
+func TestLRUWithoutTTLKeepsEntries(t *testing.T) {
+	lru := NewLRU(10, 0)
+	lru.Set("a", []byte("1"))
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := lru.Get("a"); !ok {
+		t.Error("a expired without a ttl")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	lru := NewLRU(10, 0)
+	lru.Set("a", []byte("1"))
+	lru.Set("b", []byte("2"))
+	lru.Delete("a", "missing")
+
+	if _, ok := lru.Get("a"); ok {
+		t.Error("a was not deleted")
+	}
+	if _, ok := lru.Get("b"); !ok {
+		t.Error("b was deleted")
+	}
+}
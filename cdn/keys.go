@@ -0,0 +1,32 @@
+package cdn
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const (
+	ArticlesKey   = "articles"
+	CategoriesKey = "categories"
+)
+
+func ArticleKey(articleID int64) string {
+	return "article-" + strconv.FormatInt(articleID, 10)
+}
+
+func CategoryKey(categoryID int64) string {
+	return "category-" + strconv.FormatInt(categoryID, 10)
+}
+
+// KeysForEvent returns the surrogate keys whose cached responses are stale
+// after the given change. Category changes also purge article listings since
+// articles embed their category's name and slug.
+func KeysForEvent(event model.ChangeEvent) []string {
+	if strings.HasPrefix(event.Type, "category.") {
+		return []string{CategoryKey(event.CategoryID), CategoriesKey, ArticlesKey}
+	}
+
+	return []string{ArticleKey(event.ArticleID), ArticlesKey}
+}
@@ -0,0 +1,173 @@
+package cdn
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const (
+	// purgeBatchSize is the most keys the CDN takes in one purge request.
+	purgeBatchSize  = 256
+	maxPurgeBackoff = time.Minute
+)
+
+// Purger asks the CDN to drop every response tagged with a surrogate key
+// touched by a change. Keys are collected for a short interval and sent in
+// requests of up to purgeBatchSize keys, so bulk writes don't turn into
+// thousands of purge calls. Keys of a failed request go back to the pending
+// set and are retried with a growing delay.
+type Purger struct {
+	URL         string
+	TokenHeader string
+	Token       string
+	Interval    time.Duration
+	Client      *http.Client
+
+	mutex   sync.Mutex
+	pending map[string]bool
+	full    chan struct{}
+}
+
+func NewPurger(url string, tokenHeader string, token string) *Purger {
+	purger := &Purger{
+		URL:         url,
+		TokenHeader: tokenHeader,
+		Token:       token,
+		Interval:    time.Second,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		pending:     map[string]bool{},
+		full:        make(chan struct{}, 1),
+	}
+
+	go purger.run()
+	return purger
+}
+
+// OnChange merges the event's keys into the pending set and never waits, so
+// a slow CDN cannot hold up the outbox relay that calls it.
+func (purger *Purger) OnChange(event model.ChangeEvent) {
+	purger.mutex.Lock()
+	for _, key := range KeysForEvent(event) {
+		purger.pending[key] = true
+	}
+	full := len(purger.pending) >= purgeBatchSize
+	purger.mutex.Unlock()
+
+	if full {
+		select {
+		case purger.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (purger *Purger) run() {
+	ticker := time.NewTicker(purger.Interval)
+	defer ticker.Stop()
+
+	var backoff time.Duration
+	var retryAt time.Time
+	for {
+		select {
+		case <-purger.full:
+		case <-ticker.C:
+		}
+
+		if time.Now().Before(retryAt) {
+			continue
+		}
+
+		keys := purger.takePending()
+		if len(keys) == 0 {
+			continue
+		}
+
+		if err := purger.purgeAll(keys); err != nil {
+			backoff = nextBackoff(backoff, purger.Interval)
+			retryAt = time.Now().Add(backoff)
+			log.Printf("cdn purge failed, retrying in %s: %v", backoff, err)
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// purgeAll sends the keys in batches. When a batch fails, it and the batches
+// not sent yet are put back into the pending set.
+func (purger *Purger) purgeAll(keys []string) error {
+	for start := 0; start < len(keys); start += purgeBatchSize {
+		end := start + purgeBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		if err := purger.purge(keys[start:end]); err != nil {
+			purger.restorePending(keys[start:])
+			return err
+		}
+	}
+	return nil
+}
+
+func (purger *Purger) restorePending(keys []string) {
+	purger.mutex.Lock()
+	defer purger.mutex.Unlock()
+
+	for _, key := range keys {
+		purger.pending[key] = true
+	}
+}
+
+func nextBackoff(backoff time.Duration, initial time.Duration) time.Duration {
+	if backoff == 0 {
+		return initial
+	}
+	if backoff *= 2; backoff > maxPurgeBackoff {
+		return maxPurgeBackoff
+	}
+	return backoff
+}
+
+func (purger *Purger) takePending() []string {
+	purger.mutex.Lock()
+	defer purger.mutex.Unlock()
+
+	keys := make([]string, 0, len(purger.pending))
+	for key := range purger.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	purger.pending = map[string]bool{}
+	return keys
+}
+
+func (purger *Purger) purge(keys []string) error {
+	request, err := http.NewRequest(http.MethodPost, purger.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+	if purger.Token != "" {
+		request.Header.Set(purger.TokenHeader, purger.Token)
+	}
+
+	response, err := purger.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/muhammadrijalkamal/backendtest/importer"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+)
+
+// runCommand runs a command-line subcommand instead of the HTTP server.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import-markdown":
+		return importMarkdown(args[1:])
+	case "import-wxr":
+		return importWXR(args[1:])
+	case "export":
+		return exportArchive(args[1:])
+	case "import":
+		return importArchive(args[1:])
+	}
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func importMarkdown(args []string) error {
+	flags := flag.NewFlagSet("import-markdown", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", true, "only report what would be imported")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: backendtest import-markdown [-dry-run=false] <directory|archive>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	files, err := readImportSource(flags.Arg(0), importer.MarkdownExtensions)
+	if err != nil {
+		return err
+	}
+
+	importService, err := newImportService()
+	if err != nil {
+		return err
+	}
+
+	return printImportReport(importService.ImportMarkdown(files, *dryRun), "files")
+}
+
+func importWXR(args []string) error {
+	flags := flag.NewFlagSet("import-wxr", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", true, "only report what would be imported")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: backendtest import-wxr [-dry-run=false] <export.xml>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	export, err := importer.ReadWXR(file)
+	if err != nil {
+		return err
+	}
+
+	importService, err := newImportService()
+	if err != nil {
+		return err
+	}
+
+	return printImportReport(importService.ImportWXR(export, *dryRun), "posts")
+}
+
+func exportArchive(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "write the archive to this file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: backendtest export [-o archive.zip]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	backupService, err := newBackupService()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return backupService.Export(os.Stdout)
+	}
+
+	// Write next to the target and rename, so a failed export never leaves
+	// a partial archive under the requested name.
+	file, err := ioutil.TempFile(filepath.Dir(*output), ".export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := backupService.Export(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), *output)
+}
+
+func importArchive(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: backendtest import <archive.zip>")
+		fmt.Fprintln(flags.Output(), "restores an export into an empty database, keeping the original IDs")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	backupService, err := newBackupService()
+	if err != nil {
+		return err
+	}
+
+	report, err := backupService.Restore(file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func newBackupService() (service.BackupService, error) {
+	blobStore, err := storage.NewLocalBlobStore(getenv("MEDIA_DIR", "media"))
+	if err != nil {
+		return nil, err
+	}
+
+	backupRepository := repository.NewBackupRepository(Connection)
+	return service.NewBackupService(&backupRepository, blobStore), nil
+}
+
+func printImportReport(report *model.ImportReport, noun string) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d %s could not be imported", report.Failed, noun)
+	}
+	return nil
+}
+
+func newImportService() (service.ImportService, error) {
+	blobStore, err := storage.NewLocalBlobStore(getenv("MEDIA_DIR", "media"))
+	if err != nil {
+		return nil, err
+	}
+
+	articleRepository := repository.NewArticleRepository(Connection, blobStore)
+	categoryRepository := repository.NewCategoryRepository(Connection)
+	categoryService := service.NewCategoryService(&categoryRepository)
+	importRepository := repository.NewImportRepository(Connection)
+	return service.NewImportService(&articleRepository, &importRepository, &categoryService), nil
+}
+
+func readImportSource(path string, extensions []string) ([]importer.SourceFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return importer.ReadDir(path, extensions...)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return importer.ReadArchive(file, info.Size(), extensions...)
+}
@@ -0,0 +1,252 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	FormatMarkdown  = "markdown"
+	FormatHTML      = "html"
+	FormatPlaintext = "plaintext"
+)
+
+const (
+	excerptLength  = 200
+	wordsPerMinute = 200
+)
+
+type Rendered struct {
+	HTML               string
+	Text               string
+	TOC                []model.TOCEntry
+	Excerpt            string
+	WordCount          int
+	ReadingTimeMinutes int
+}
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		// Raw HTML is let through here and cleaned by the sanitizer below, the
+		// same way HTML articles are.
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+	policy     = bluemonday.UGCPolicy()
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+func ValidateFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return FormatMarkdown, nil
+	case FormatMarkdown, FormatHTML, FormatPlaintext:
+		return format, nil
+	default:
+		return "", errors.New("invalid content format")
+	}
+}
+
+// Render turns the article source into sanitized HTML, a plain-text version
+// and a table of contents built from the headings, which get stable anchors.
+func Render(format string, source string) (*Rendered, error) {
+	var unsafe string
+	switch format {
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(source), &buf); err != nil {
+			return nil, err
+		}
+		unsafe = buf.String()
+	case FormatHTML:
+		unsafe = source
+	case FormatPlaintext:
+		unsafe = plaintextHTML(source)
+	default:
+		return nil, errors.New("invalid content format")
+	}
+
+	sanitized := policy.Sanitize(unsafe)
+	nodes, err := nethtml.ParseFragment(strings.NewReader(sanitized), &nethtml.Node{
+		Type:     nethtml.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := Rendered{TOC: []model.TOCEntry{}}
+	anchors := map[string]int{}
+	var out, text bytes.Buffer
+	for _, node := range nodes {
+		walk(node, func(n *nethtml.Node) {
+			level := headingLevel(n)
+			if level == 0 {
+				return
+			}
+
+			title := strings.TrimSpace(collapse(nodeText(n)))
+			id := anchor(title, anchors)
+			n.Attr = append(n.Attr, nethtml.Attribute{Key: "id", Val: id})
+			rendered.TOC = append(rendered.TOC, model.TOCEntry{Level: level, ID: id, Title: title})
+		})
+
+		if err := nethtml.Render(&out, node); err != nil {
+			return nil, err
+		}
+		writeText(&text, node)
+	}
+
+	rendered.HTML = out.String()
+	rendered.Text = strings.TrimSpace(collapseLines(text.String()))
+	rendered.WordCount = len(strings.Fields(rendered.Text))
+	rendered.ReadingTimeMinutes = (rendered.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	rendered.Excerpt = excerpt(rendered.Text)
+	return &rendered, nil
+}
+
+// excerpt cuts the plain text at the last word boundary before the limit.
+func excerpt(text string) string {
+	text = collapse(text)
+	runes := []rune(text)
+	if len(runes) <= excerptLength {
+		return text
+	}
+
+	cut := string(runes[:excerptLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:!?-") + "…"
+}
+
+func plaintextHTML(source string) string {
+	var buf strings.Builder
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		buf.WriteString("<p>")
+		buf.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		buf.WriteString("</p>\n")
+	}
+	return buf.String()
+}
+
+func walk(n *nethtml.Node, fn func(*nethtml.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func headingLevel(n *nethtml.Node) int {
+	if n.Type != nethtml.ElementNode {
+		return 0
+	}
+
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+func anchor(title string, anchors map[string]int) string {
+	id := slug.Make(title)
+	if id == "" {
+		id = "section"
+	}
+
+	anchors[id]++
+	if anchors[id] > 1 {
+		id += "-" + strconv.Itoa(anchors[id]-1)
+	}
+	return id
+}
+
+func nodeText(n *nethtml.Node) string {
+	var buf bytes.Buffer
+	walk(n, func(c *nethtml.Node) {
+		if c.Type == nethtml.TextNode {
+			buf.WriteString(c.Data)
+		}
+	})
+	return buf.String()
+}
+
+// writeText extracts the visible text, putting block-level elements on their
+// own lines so words from adjacent paragraphs do not run together.
+func writeText(buf *bytes.Buffer, n *nethtml.Node) {
+	if n.Type == nethtml.TextNode {
+		buf.WriteString(whitespace.ReplaceAllString(n.Data, " "))
+		return
+	}
+
+	block := n.Type == nethtml.ElementNode && isBlock(n.DataAtom)
+	if block {
+		buf.WriteString("\n")
+	}
+	if n.Type == nethtml.ElementNode && n.DataAtom == atom.Br {
+		buf.WriteString("\n")
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(buf, c)
+	}
+
+	if block {
+		buf.WriteString("\n")
+	}
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Blockquote, atom.Pre, atom.Table, atom.Tr,
+		atom.Hr, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Figure, atom.Figcaption:
+		return true
+	}
+	return false
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func collapseLines(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = collapse(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// adminOnly guards a route with the ADMIN_TOKEN bearer token. Without a
+// configured token the route is disabled altogether.
+func adminOnly(token string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if token == "" {
+			return fiber.NewError(fiber.StatusForbidden, "admin routes are disabled, set ADMIN_TOKEN to enable them")
+		}
+
+		given := strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return fiber.NewError(fiber.StatusUnauthorized, "a valid admin token is required")
+		}
+
+		return ctx.Next()
+	}
+}
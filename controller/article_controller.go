@@ -1,7 +1,14 @@
 package controller
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
 	"github.com/muhammadrijalkamal/backendtest/model"
 	"github.com/muhammadrijalkamal/backendtest/service"
 	"github.com/muhammadrijalkamal/backendtest/util"
@@ -19,9 +26,14 @@ func NewArticleController(articleService *service.ArticleService) ArticleControl
 
 func (controller *ArticleController) SetupRoutes(app *fiber.App) {
 	app.Get("/article/deleted", controller.ListSoftDeleted)
+	app.Delete("/article/deleted/bulk", controller.DeleteBulk)
 	app.Delete("/article/deleted/:id", controller.Delete)
 	app.Post("/article", controller.Create)
+	app.Post("/article/bulk", controller.CreateBulk)
+	app.Delete("/article/bulk", controller.SoftDeleteBulk)
+	app.Post("/article/move", controller.MoveToCategory)
 	app.Get("/article", controller.List)
+	app.Get("/article/slug/:slug", controller.FindOneBySlug)
 	app.Get("/article/:id", controller.FindOne)
 	app.Put("/article/:id", controller.Update)
 	app.Delete("/article/:id", controller.SoftDelete)
@@ -29,42 +41,91 @@ func (controller *ArticleController) SetupRoutes(app *fiber.App) {
 
 func (controller *ArticleController) Create(ctx *fiber.Ctx) error {
 	var request *model.ArticleCreateRequest
-	parserErr := ctx.BodyParser(&request)
-	util.ReturnErrorIfNeeded(parserErr)
+	if err := parseBody(ctx, &request); err != nil {
+		return err
+	}
 
-	controller.ArticleService.Create(request)
+	article := controller.ArticleService.Create(request)
 
-	return ctx.Status(fiber.StatusCreated).JSON(model.SuccessResponse{
+	ctx.Location("/article/" + strconv.FormatInt(article.ID, 10))
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
 		StatusCode: fiber.StatusCreated,
-		Data:       "Article created",
+		Data:       article,
 	})
 }
 
 func (controller *ArticleController) List(ctx *fiber.Ctx) error {
 	title := ctx.Query("title")
+	selection := articleSelection(ctx, true)
+
+	if wantsCSV(ctx) {
+		columns := selection.Fields
+		if selection.Expands("category") {
+			columns = append(append([]string{}, columns...), expandColumns("category", model.CategoryFields)...)
+		}
+
+		return streamCSV(ctx, "articles.csv", columns, func(write func(record interface{}) error) error {
+			return controller.ArticleService.Stream(title, selection, func(article *model.ArticleResponse) error {
+				return write(article)
+			})
+		})
+	}
 
 	var articles *[]model.ArticleResponse
 
 	if title != "" {
-		articles = controller.ArticleService.ListByTitle(title)
+		articles = controller.ArticleService.ListByTitle(title, selection)
 	} else {
-		articles = controller.ArticleService.List()
+		articles = controller.ArticleService.List(selection)
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	addSurrogateKeys(ctx, cdn.ArticlesKey)
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       articles,
+		Data:       project(articles, selection),
 	})
 }
 
 func (controller *ArticleController) FindOne(ctx *fiber.Ctx) error {
 	articleID := ctx.Params("id")
 
-	article := controller.ArticleService.FindOne(articleID)
+	selection := articleSelection(ctx, false)
+
+	article := controller.ArticleService.FindOne(articleID, selection)
+	if article == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	id, _ := strconv.ParseInt(articleID, 10, 64)
+	return controller.respondArticle(ctx, id, article, selection)
+}
+
+func (controller *ArticleController) FindOneBySlug(ctx *fiber.Ctx) error {
+	selection := articleSelection(ctx, false)
+
+	article := controller.ArticleService.FindOneBySlug(ctx.Params("slug"), selection)
+	if article == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	return controller.respondArticle(ctx, article.ID, article, selection)
+}
+
+func (controller *ArticleController) respondArticle(ctx *fiber.Ctx, id int64, article *model.ArticleResponse, selection *model.Selection) error {
+	addSurrogateKeys(ctx, cdn.ArticleKey(id))
+	if article.Category != nil {
+		addSurrogateKeys(ctx, cdn.CategoryKey(article.Category.ID))
+		setLastModified(ctx, article.CreatedAt, article.UpdatedAt, article.Category.CreatedAt, article.Category.UpdatedAt)
+	} else {
+		if article.CategoryID != 0 {
+			addSurrogateKeys(ctx, cdn.CategoryKey(article.CategoryID))
+		}
+		setLastModified(ctx, article.CreatedAt, article.UpdatedAt)
+	}
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       article,
+		Data:       project(article, selection),
 	})
 }
 
@@ -72,14 +133,15 @@ func (controller *ArticleController) Update(ctx *fiber.Ctx) error {
 	articleID := ctx.Params("id")
 
 	var request *model.ArticleUpdateRequest
-	parserErr := ctx.BodyParser(&request)
-	util.ReturnErrorIfNeeded(parserErr)
+	if err := parseBody(ctx, &request); err != nil {
+		return err
+	}
 
-	controller.ArticleService.Update(articleID, request)
+	article := controller.ArticleService.Update(articleID, request)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       "Article updated",
+		Data:       article,
 	})
 }
 
@@ -88,17 +150,19 @@ func (controller *ArticleController) SoftDelete(ctx *fiber.Ctx) error {
 
 	controller.ArticleService.SoftDelete(articleID)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       "Article deleted",
 	})
 }
 
 func (controller *ArticleController) ListSoftDeleted(ctx *fiber.Ctx) error {
-	articles := controller.ArticleService.ListSoftDeleted()
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	selection := articleSelection(ctx, true)
+
+	articles := controller.ArticleService.ListSoftDeleted(selection)
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       articles,
+		Data:       project(articles, selection),
 	})
 }
 
@@ -107,8 +171,93 @@ func (controller *ArticleController) Delete(ctx *fiber.Ctx) error {
 
 	controller.ArticleService.Delete(articleID)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       "Article deleted from database",
 	})
 }
+
+func (controller *ArticleController) MoveToCategory(ctx *fiber.Ctx) error {
+	var request *model.ArticleMoveRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	result := controller.ArticleService.MoveToCategory(request)
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       result,
+	})
+}
+
+func (controller *ArticleController) CreateBulk(ctx *fiber.Ctx) error {
+	items, parserErr := parseBulkItems(ctx)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	result := controller.ArticleService.CreateBulk(items, ctx.Query("mode"))
+
+	statusCode := bulkStatusCode(result, fiber.StatusCreated)
+	return Render(ctx, statusCode, model.SuccessResponse{
+		StatusCode: statusCode,
+		Data:       result,
+	})
+}
+
+func (controller *ArticleController) SoftDeleteBulk(ctx *fiber.Ctx) error {
+	var request *model.BulkDeleteRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	result := controller.ArticleService.SoftDeleteBulk(request, ctx.Query("mode"))
+
+	statusCode := bulkStatusCode(result, fiber.StatusOK)
+	return Render(ctx, statusCode, model.SuccessResponse{
+		StatusCode: statusCode,
+		Data:       result,
+	})
+}
+
+func (controller *ArticleController) DeleteBulk(ctx *fiber.Ctx) error {
+	var request *model.BulkDeleteRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	result := controller.ArticleService.DeleteBulk(request, ctx.Query("mode"))
+
+	statusCode := bulkStatusCode(result, fiber.StatusOK)
+	return Render(ctx, statusCode, model.SuccessResponse{
+		StatusCode: statusCode,
+		Data:       result,
+	})
+}
+
+// parseBulkItems splits the body into raw items, either from a JSON array or
+// from newline-delimited JSON, leaving per-item decoding to the service.
+func parseBulkItems(ctx *fiber.Ctx) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	contentType := ctx.Get(fiber.HeaderContentType)
+	if !strings.HasPrefix(contentType, "application/x-ndjson") && !strings.HasPrefix(contentType, "application/ndjson") {
+		err := json.Unmarshal(ctx.Body(), &items)
+		return items, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(ctx.Body()))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(append([]byte(nil), line...)))
+	}
+
+	return items, scanner.Err()
+}
+
+func bulkStatusCode(result *model.BulkResponse, success int) int {
+	if result.Failed == 0 {
+		return success
+	}
+
+	return fiber.StatusMultiStatus
+}
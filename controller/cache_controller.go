@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type CacheController struct {
+	Cache    cache.Cache
+	Counters *cache.Counters
+}
+
+func NewCacheController(c cache.Cache, counters *cache.Counters) CacheController {
+	return CacheController{
+		Cache:    c,
+		Counters: counters,
+	}
+}
+
+func (controller *CacheController) SetupRoutes(app *fiber.App) {
+	app.Get("/cache/stats", controller.Stats)
+}
+
+func (controller *CacheController) Stats(ctx *fiber.Ctx) error {
+	stats := controller.Counters.Stats()
+
+	if lru, ok := controller.Cache.(*cache.LRU); ok {
+		entries, evictions := lru.Len(), lru.Evictions()
+		stats.Entries = &entries
+		stats.Evictions = &evictions
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       stats,
+	})
+}
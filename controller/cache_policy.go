@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultRoute = "*"
+
+type RoutePolicy struct {
+	CacheControl  string   `json:"cache_control"`
+	SurrogateKeys []string `json:"surrogate_keys"`
+}
+
+// CachePolicy maps registered route paths (e.g. "/article/:id") to the
+// caching headers sent with their successful GET responses. Routes without
+// an entry fall back to the "*" entry.
+type CachePolicy map[string]RoutePolicy
+
+func DefaultCachePolicy() CachePolicy {
+	feed := RoutePolicy{CacheControl: "public, max-age=300"}
+	sitemap := RoutePolicy{CacheControl: "public, max-age=3600"}
+	private := RoutePolicy{CacheControl: "no-store"}
+
+	return CachePolicy{
+		defaultRoute:                           {CacheControl: "no-cache"},
+		"/article":                             {CacheControl: "public, max-age=60"},
+		"/article/:id":                         {CacheControl: "public, max-age=300"},
+		"/article/slug/:slug":                  {CacheControl: "public, max-age=300"},
+		"/article/deleted":                     private,
+		"/category":                            {CacheControl: "public, max-age=300"},
+		"/category/:id":                        {CacheControl: "public, max-age=300"},
+		"/category/slug/:slug":                 {CacheControl: "public, max-age=300"},
+		"/category/deleted":                    private,
+		"/feed.rss":                            feed,
+		"/feed.atom":                           feed,
+		"/feed.json":                           feed,
+		"/category/:slug/feed.rss":             feed,
+		"/category/:slug/feed.atom":            feed,
+		"/category/:slug/feed.json":            feed,
+		"/sitemap.xml":                         sitemap,
+		"/sitemap-:page.xml":                   sitemap,
+		"/article/:id/comments":                {CacheControl: "public, max-age=60"},
+		"/comments/moderation":                 private,
+		"/comments/deleted":                    private,
+		"/media/:id":                           {CacheControl: "public, max-age=300"},
+		"/media/:id/variants/:size":            {CacheControl: mediaCacheControl},
+		"/article/:id/attachments":             {CacheControl: "public, max-age=60"},
+		"/cache/stats":                         private,
+		"/export":                              private,
+		"/events/stream":                       private,
+		"/webhooks":                            private,
+		"/webhooks/:id":                        private,
+		"/webhooks/:id/deliveries":             private,
+		"/webhooks/:id/deliveries/:deliveryId": private,
+		"/openapi.json":                        {CacheControl: "public, max-age=300"},
+		"/docs":                                {CacheControl: "public, max-age=300"},
+	}
+}
+
+// ParseCachePolicy overlays a JSON object of route policies, as found in the
+// HTTP_CACHE_POLICY setting, onto the defaults.
+func ParseCachePolicy(overrides string) (CachePolicy, error) {
+	policy := DefaultCachePolicy()
+	if strings.TrimSpace(overrides) == "" {
+		return policy, nil
+	}
+
+	var routes map[string]RoutePolicy
+	if err := json.Unmarshal([]byte(overrides), &routes); err != nil {
+		return nil, err
+	}
+
+	for route, routePolicy := range routes {
+		policy[route] = routePolicy
+	}
+
+	return policy, nil
+}
+
+func (policy CachePolicy) route(path string) RoutePolicy {
+	if routePolicy, ok := policy[path]; ok {
+		return routePolicy
+	}
+	return policy[defaultRoute]
+}
+
+// ConditionalGET adds Cache-Control, Surrogate-Key and a strong ETag to
+// successful GET responses and answers matching If-None-Match or
+// If-Modified-Since requests with 304. Handlers contribute a Last-Modified
+// header and surrogate keys derived from the data they return.
+func ConditionalGET(policy CachePolicy) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		method := ctx.Method()
+		if method != fiber.MethodGet && method != fiber.MethodHead {
+			return ctx.Next()
+		}
+
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+
+		if ctx.Response().StatusCode() != fiber.StatusOK || ctx.Response().IsBodyStream() {
+			return nil
+		}
+
+		routePolicy := policy.route(ctx.Route().Path)
+		if routePolicy.CacheControl != "" && len(ctx.Response().Header.Peek(fiber.HeaderCacheControl)) == 0 {
+			ctx.Set(fiber.HeaderCacheControl, routePolicy.CacheControl)
+		}
+
+		keys := append(surrogateKeys(ctx), routePolicy.SurrogateKeys...)
+		if len(keys) > 0 {
+			ctx.Set(headerSurrogateKey, strings.Join(keys, " "))
+		}
+
+		etag := string(ctx.Response().Header.Peek(fiber.HeaderETag))
+		if etag == "" {
+			etag = strongETag(ctx.Response().Body())
+		}
+
+		lastModified, _ := http.ParseTime(string(ctx.Response().Header.Peek(fiber.HeaderLastModified)))
+		if notModified(ctx, etag, lastModified) {
+			ctx.Response().ResetBody()
+			ctx.Status(fiber.StatusNotModified)
+		}
+
+		return nil
+	}
+}
@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
 	"github.com/muhammadrijalkamal/backendtest/model"
 	"github.com/muhammadrijalkamal/backendtest/service"
 	"github.com/muhammadrijalkamal/backendtest/util"
@@ -22,9 +25,11 @@ func (controller *CategoryController) SetupRoutes(app *fiber.App) {
 	app.Delete("/category/deleted/:id", controller.Delete)
 	app.Post("/category", controller.Create)
 	app.Get("/category", controller.List)
+	app.Get("/category/slug/:slug", controller.FindOneBySlug)
 	app.Get("/category/:id", controller.FindOne)
 	app.Put("/category/:id", controller.Update)
 	app.Delete("/category/:id", controller.SoftDelete)
+	app.Post("/category/:id/merge", controller.Merge)
 }
 
 func (controller *CategoryController) Create(ctx *fiber.Ctx) error {
@@ -32,28 +37,88 @@ func (controller *CategoryController) Create(ctx *fiber.Ctx) error {
 	parserErr := ctx.BodyParser(&request)
 	util.ReturnErrorIfNeeded(parserErr)
 
-	controller.CategoryService.Create(request)
+	category := controller.CategoryService.Create(request)
 
-	return ctx.Status(fiber.StatusCreated).JSON(model.SuccessResponse{
+	ctx.Location("/category/" + strconv.FormatInt(category.ID, 10))
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
 		StatusCode: fiber.StatusCreated,
-		Data:       "Category created",
+		Data:       category,
 	})
 }
 
 func (controller *CategoryController) List(ctx *fiber.Ctx) error {
-	categories := controller.CategoryService.List()
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	selection := categorySelection(ctx)
+
+	if wantsCSV(ctx) {
+		columns := selection.Fields
+		if selection.Expands("stats") {
+			columns = append(append([]string{}, columns...), expandColumns("stats", model.CategoryStatsFields)...)
+		}
+
+		return streamCSV(ctx, "categories.csv", columns, func(write func(record interface{}) error) error {
+			return controller.CategoryService.Stream(selection.Expands("stats"), func(category *model.CategoryResponse) error {
+				return write(category)
+			})
+		})
+	}
+
+	var categories *[]model.CategoryResponse
+
+	if selection.Expands("stats") {
+		categories = controller.CategoryService.ListWithStats()
+	} else {
+		categories = controller.CategoryService.List()
+	}
+
+	addSurrogateKeys(ctx, cdn.CategoriesKey)
+	if selection.Expands("stats") {
+		addSurrogateKeys(ctx, cdn.ArticlesKey)
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       categories,
+		Data:       project(categories, selection),
 	})
 }
 
 func (controller *CategoryController) FindOne(ctx *fiber.Ctx) error {
 	categoryID := ctx.Params("id")
 
-	category := controller.CategoryService.FindOne(categoryID)
+	selection := categorySelection(ctx)
+
+	var category *model.CategoryResponse
+
+	if selection.Expands("stats") {
+		category = controller.CategoryService.FindOneWithStats(categoryID)
+	} else {
+		category = controller.CategoryService.FindOne(categoryID)
+	}
+
+	if category == nil {
+		return fiber.NewError(fiber.StatusNotFound, "category not found")
+	}
+
+	categoryValidators(ctx, category, selection.Expands("stats"))
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       project(category, selection),
+	})
+}
+
+func (controller *CategoryController) FindOneBySlug(ctx *fiber.Ctx) error {
+	categorySlug := ctx.Params("slug")
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	category := controller.CategoryService.FindOneBySlug(categorySlug)
+	if category == nil {
+		return fiber.NewError(fiber.StatusNotFound, "category not found")
+	}
+
+	if category.CategorySlug != categorySlug {
+		return ctx.Redirect("/category/slug/"+category.CategorySlug, fiber.StatusMovedPermanently)
+	}
+
+	categoryValidators(ctx, category, false)
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       category,
 	})
@@ -66,11 +131,11 @@ func (controller *CategoryController) Update(ctx *fiber.Ctx) error {
 	parserErr := ctx.BodyParser(&request)
 	util.ReturnErrorIfNeeded(parserErr)
 
-	controller.CategoryService.Update(categoryID, request)
+	category := controller.CategoryService.Update(categoryID, request)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
-		Data:       "Category updated",
+		Data:       category,
 	})
 }
 
@@ -79,7 +144,7 @@ func (controller *CategoryController) SoftDelete(ctx *fiber.Ctx) error {
 
 	controller.CategoryService.SoftDelete(categoryID)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       "Category deleted",
 	})
@@ -87,7 +152,7 @@ func (controller *CategoryController) SoftDelete(ctx *fiber.Ctx) error {
 
 func (controller *CategoryController) ListSoftDeleted(ctx *fiber.Ctx) error {
 	categories := controller.CategoryService.ListSoftDeleted()
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       categories,
 	})
@@ -98,8 +163,36 @@ func (controller *CategoryController) Delete(ctx *fiber.Ctx) error {
 
 	controller.CategoryService.Delete(categoryID)
 
-	return ctx.Status(fiber.StatusOK).JSON(model.SuccessResponse{
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
 		StatusCode: fiber.StatusOK,
 		Data:       "Category deleted from database",
 	})
 }
+
+func (controller *CategoryController) Merge(ctx *fiber.Ctx) error {
+	categoryID := ctx.Params("id")
+
+	var request *model.CategoryMergeRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	category := controller.CategoryService.Merge(categoryID, request)
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       category,
+	})
+}
+
+// categoryValidators tags a single category response. Stats are derived from
+// the category's articles, so those responses carry no Last-Modified and are
+// purged along with the article listings.
+func categoryValidators(ctx *fiber.Ctx, category *model.CategoryResponse, withStats bool) {
+	addSurrogateKeys(ctx, cdn.CategoryKey(category.ID))
+	if withStats {
+		addSurrogateKeys(ctx, cdn.ArticlesKey)
+		return
+	}
+
+	setLastModified(ctx, category.CreatedAt, category.UpdatedAt)
+}
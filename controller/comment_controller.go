@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+type CommentController struct {
+	CommentService service.CommentService
+	AdminToken     string
+}
+
+func NewCommentController(commentService *service.CommentService, adminToken string) CommentController {
+	return CommentController{
+		CommentService: *commentService,
+		AdminToken:     adminToken,
+	}
+}
+
+// SetupRoutes leaves posting and reading approved comments public. Everything
+// an editor does, and every response carrying author emails, needs the admin
+// token.
+func (controller *CommentController) SetupRoutes(app *fiber.App) {
+	admin := adminOnly(controller.AdminToken)
+	app.Post("/article/:id/comments", controller.Create)
+	app.Get("/article/:id/comments", controller.ListByArticle)
+	app.Get("/comments/moderation", admin, controller.ListForModeration)
+	app.Get("/comments/deleted", admin, controller.ListSoftDeleted)
+	app.Delete("/comments/deleted/:id", admin, controller.Delete)
+	app.Put("/comments/:id/status", admin, controller.Moderate)
+	app.Delete("/comments/:id", admin, controller.SoftDelete)
+}
+
+func (controller *CommentController) Create(ctx *fiber.Ctx) error {
+	var request *model.CommentCreateRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	comment := controller.CommentService.Create(ctx.Params("id"), request)
+	if comment == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	comment.AuthorEmail = ""
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
+		StatusCode: fiber.StatusCreated,
+		Data:       comment,
+	})
+}
+
+func (controller *CommentController) ListByArticle(ctx *fiber.Ctx) error {
+	comments := controller.CommentService.ListByArticle(ctx.Params("id"))
+	if comments == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	if articleID, err := strconv.ParseInt(ctx.Params("id"), 10, 64); err == nil {
+		addSurrogateKeys(ctx, cdn.ArticleKey(articleID))
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       comments,
+	})
+}
+
+func (controller *CommentController) ListForModeration(ctx *fiber.Ctx) error {
+	comments := controller.CommentService.ListForModeration(ctx.Query("status"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       comments,
+	})
+}
+
+func (controller *CommentController) ListSoftDeleted(ctx *fiber.Ctx) error {
+	comments := controller.CommentService.ListSoftDeleted()
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       comments,
+	})
+}
+
+func (controller *CommentController) Moderate(ctx *fiber.Ctx) error {
+	var request *model.CommentModerateRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	comment := controller.CommentService.Moderate(ctx.Params("id"), request)
+	if comment == nil {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       comment,
+	})
+}
+
+func (controller *CommentController) SoftDelete(ctx *fiber.Ctx) error {
+	controller.CommentService.SoftDelete(ctx.Params("id"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       "Comment deleted",
+	})
+}
+
+func (controller *CommentController) Delete(ctx *fiber.Ctx) error {
+	controller.CommentService.Delete(ctx.Params("id"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       "Comment deleted from database",
+	})
+}
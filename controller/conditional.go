@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	headerSurrogateKey = "Surrogate-Key"
+	localSurrogateKeys = "surrogate_keys"
+)
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the validators on the response and reports whether the
+// request's If-None-Match or If-Modified-Since precondition still matches,
+// in which case the caller should answer 304 without a body.
+func notModified(ctx *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		ctx.Set(fiber.HeaderETag, etag)
+	}
+
+	if !lastModified.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := ctx.Get(fiber.HeaderIfNoneMatch); ifNoneMatch != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ifModifiedSince := ctx.Get(fiber.HeaderIfModifiedSince); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setLastModified sends the latest non-zero timestamp as Last-Modified.
+func setLastModified(ctx *fiber.Ctx, timestamps ...time.Time) {
+	var latest time.Time
+	for _, timestamp := range timestamps {
+		if timestamp.After(latest) {
+			latest = timestamp
+		}
+	}
+
+	if !latest.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, latest.UTC().Format(http.TimeFormat))
+	}
+}
+
+func addSurrogateKeys(ctx *fiber.Ctx, keys ...string) {
+	ctx.Locals(localSurrogateKeys, append(surrogateKeys(ctx), keys...))
+}
+
+func surrogateKeys(ctx *fiber.Ctx) []string {
+	keys, _ := ctx.Locals(localSurrogateKeys).([]string)
+	return keys
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	mimeTextCSV = "text/csv"
+	zeroTime    = "0001-01-01T00:00:00Z"
+)
+
+// wantsCSV picks CSV over JSON when asked for with format=csv or an Accept
+// header that prefers text/csv. format= wins over the header.
+func wantsCSV(ctx *fiber.Ctx) bool {
+	ctx.Vary(fiber.HeaderAccept)
+
+	switch ctx.Query("format") {
+	case "csv":
+		return true
+	case "json":
+		return false
+	case "":
+		return ctx.Accepts(fiber.MIMEApplicationJSON, mimeTextCSV) == mimeTextCSV
+	}
+
+	panic(fiber.NewError(fiber.StatusBadRequest, "format must be json or csv"))
+}
+
+// expandColumns prefixes the fields of an expanded object with its name, as
+// in "category.category_name".
+func expandColumns(name string, fields []string) []string {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = name + "." + field
+	}
+	return columns
+}
+
+// streamCSV writes an RFC 4180 document with one row per record that each
+// hands over. Records are encoded like the JSON response and the columns
+// looked up in it, so both formats always agree. Nothing is buffered beyond
+// the response writer; an error after the header was sent can only cut the
+// document short and is logged.
+func streamCSV(ctx *fiber.Ctx, filename string, columns []string, each func(write func(record interface{}) error) error) error {
+	ctx.Set(fiber.HeaderContentType, mimeTextCSV+"; charset=utf-8")
+	ctx.Set(fiber.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer := csv.NewWriter(w)
+		writer.UseCRLF = true
+		writer.Write(columns)
+
+		row := make([]string, len(columns))
+		err := each(func(record interface{}) error {
+			object, err := csvObject(record)
+			if err != nil {
+				return err
+			}
+
+			for i, column := range columns {
+				row[i] = csvValue(lookup(object, column))
+			}
+			return writer.Write(row)
+		})
+
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		if err != nil {
+			log.Printf("csv: %s: %v", filename, err)
+		}
+	})
+	return nil
+}
+
+func csvObject(record interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	return object, decoder.Decode(&object)
+}
+
+func lookup(object map[string]interface{}, column string) interface{} {
+	path := strings.Split(column, ".")
+	for _, key := range path[:len(path)-1] {
+		nested, ok := object[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		object = nested
+	}
+	return object[path[len(path)-1]]
+}
+
+// csvValue writes unset times as empty cells and nested values as JSON.
+// Text starting with a formula character is prefixed with an apostrophe so
+// spreadsheets show it instead of evaluating it.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if v == zeroTime {
+			return ""
+		}
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+const (
+	eventStreamHeartbeat = 15 * time.Second
+	eventStreamRetryMS   = 3000
+)
+
+type EventStreamController struct {
+	EventStreamService service.EventStreamService
+}
+
+func NewEventStreamController(eventStreamService *service.EventStreamService) EventStreamController {
+	return EventStreamController{
+		EventStreamService: *eventStreamService,
+	}
+}
+
+func (controller *EventStreamController) SetupRoutes(app *fiber.App) {
+	app.Get("/events/stream", controller.Stream)
+}
+
+// Stream pushes change events as Server-Sent Events. Clients can narrow the
+// stream with types= (event types or wildcards such as "category.*") and
+// category_id=, and resume with the Last-Event-ID header (or last_event_id=
+// for the initial EventSource request, which cannot set headers).
+func (controller *EventStreamController) Stream(ctx *fiber.Ctx) error {
+	types := splitList(ctx.Query("types"))
+	for _, eventType := range types {
+		if !model.ValidEventPattern(eventType) {
+			return fiber.NewError(fiber.StatusBadRequest, "unknown event type "+eventType)
+		}
+	}
+
+	var categoryID int64
+	if raw := ctx.Query("category_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "category_id must be a number")
+		}
+		categoryID = id
+	}
+
+	lastEventID := ctx.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	matches := func(event model.ChangeEvent) bool {
+		if len(types) > 0 && !model.MatchEventType(types, event.Type) {
+			return false
+		}
+		return categoryID == 0 || event.CategoryID == categoryID
+	}
+
+	subscription := controller.EventStreamService.Subscribe(lastEventID)
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Cancel()
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		w.WriteString("retry: " + strconv.Itoa(eventStreamRetryMS) + "\n\n")
+		if subscription.Reset {
+			w.WriteString("event: reset\ndata: {}\n\n")
+		}
+
+		// skipped remembers the newest filtered-out event so the client's
+		// Last-Event-ID still advances past it with the next heartbeat.
+		var skipped int64
+		for _, event := range subscription.Replay {
+			if matches(event) {
+				writeStreamEvent(w, event)
+				skipped = 0
+			} else {
+				skipped = event.Sequence
+			}
+		}
+
+		for {
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return
+				}
+				if !matches(event) {
+					skipped = event.Sequence
+					continue
+				}
+				writeStreamEvent(w, event)
+				skipped = 0
+			case <-heartbeat.C:
+				if skipped != 0 {
+					w.WriteString("id: " + strconv.FormatInt(skipped, 10) + "\n\n")
+					skipped = 0
+				}
+				w.WriteString(": heartbeat\n\n")
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeStreamEvent(w *bufio.Writer, event model.ChangeEvent) {
+	data, err := json.Marshal(event.Message())
+	if err != nil {
+		return
+	}
+
+	w.WriteString("id: " + strconv.FormatInt(event.Sequence, 10) + "\n")
+	w.WriteString("event: " + event.Type + "\n")
+	w.WriteString("data: ")
+	w.Write(data)
+	w.WriteString("\n\n")
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"bufio"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+type ExportController struct {
+	BackupService service.BackupService
+	AdminToken    string
+}
+
+func NewExportController(backupService *service.BackupService, adminToken string) ExportController {
+	return ExportController{
+		BackupService: *backupService,
+		AdminToken:    adminToken,
+	}
+}
+
+func (controller *ExportController) SetupRoutes(app *fiber.App) {
+	app.Get("/export", adminOnly(controller.AdminToken), controller.Export)
+}
+
+// Export streams the archive as it is written. An error halfway through can
+// only cut the response short; the archive then lacks its manifest and is
+// rejected when restoring.
+func (controller *ExportController) Export(ctx *fiber.Ctx) error {
+	filename := "export-" + time.Now().UTC().Format("20060102T150405Z") + ".zip"
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := controller.BackupService.Export(w); err != nil {
+			log.Printf("export: %v", err)
+			return
+		}
+		w.Flush()
+	})
+	return nil
+}
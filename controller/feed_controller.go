@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
+	"github.com/muhammadrijalkamal/backendtest/feed"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+type FeedController struct {
+	FeedService     service.FeedService
+	CategoryService service.CategoryService
+	BaseURL         string
+	GUIDHost        string
+}
+
+func NewFeedController(feedService *service.FeedService, categoryService *service.CategoryService, baseURL string, guidHost string) FeedController {
+	return FeedController{
+		FeedService:     *feedService,
+		CategoryService: *categoryService,
+		BaseURL:         baseURL,
+		GUIDHost:        guidHost,
+	}
+}
+
+func (controller *FeedController) SetupRoutes(app *fiber.App) {
+	app.Get("/feed.rss", controller.RSS)
+	app.Get("/feed.atom", controller.Atom)
+	app.Get("/feed.json", controller.JSON)
+	app.Get("/category/:slug/feed.rss", controller.RSS)
+	app.Get("/category/:slug/feed.atom", controller.Atom)
+	app.Get("/category/:slug/feed.json", controller.JSON)
+}
+
+func (controller *FeedController) RSS(ctx *fiber.Ctx) error {
+	return controller.render(ctx, "feed.rss", "application/rss+xml; charset=utf-8", feed.RSS)
+}
+
+func (controller *FeedController) Atom(ctx *fiber.Ctx) error {
+	return controller.render(ctx, "feed.atom", "application/atom+xml; charset=utf-8", feed.Atom)
+}
+
+func (controller *FeedController) JSON(ctx *fiber.Ctx) error {
+	return controller.render(ctx, "feed.json", "application/feed+json; charset=utf-8", feed.JSON)
+}
+
+func (controller *FeedController) render(ctx *fiber.Ctx, name string, contentType string, build func(feed.Site, *model.FeedSource) ([]byte, error)) error {
+	baseURL := controller.BaseURL
+	if baseURL == "" {
+		baseURL = ctx.BaseURL()
+	}
+
+	var category *model.CategoryResponse
+	path := "/" + name
+	if categorySlug := ctx.Params("slug"); categorySlug != "" {
+		category = controller.CategoryService.FindOneBySlug(categorySlug)
+		if category == nil {
+			return fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+
+		path = "/category/" + category.CategorySlug + "/" + name
+		if category.CategorySlug != categorySlug {
+			return ctx.Redirect(path, fiber.StatusMovedPermanently)
+		}
+	}
+
+	source := controller.FeedService.Source(category)
+	body, buildErr := build(feed.Site{BaseURL: baseURL, SelfURL: baseURL + path, GUIDHost: controller.GUIDHost}, source)
+	util.ReturnErrorIfNeeded(buildErr)
+
+	addSurrogateKeys(ctx, cdn.ArticlesKey)
+	if category != nil {
+		addSurrogateKeys(ctx, cdn.CategoryKey(category.ID))
+	}
+	setLastModified(ctx, source.Updated)
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	return ctx.Status(fiber.StatusOK).Send(body)
+}
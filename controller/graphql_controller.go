@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/graph"
+)
+
+const (
+	mimeApplicationGraphQL         = "application/graphql"
+	mimeApplicationGraphQLResponse = "application/graphql-response+json"
+	graphQLPath                    = "/graphql"
+)
+
+// graphQLResponseTypes are offered in this order, so clients that accept
+// anything get the GraphQL media type.
+var graphQLResponseTypes = []string{mimeApplicationGraphQLResponse, fiber.MIMEApplicationJSON}
+
+type GraphQLController struct {
+	Server *graph.Server
+}
+
+func NewGraphQLController(server *graph.Server) GraphQLController {
+	return GraphQLController{
+		Server: server,
+	}
+}
+
+func (controller *GraphQLController) SetupRoutes(app *fiber.App) {
+	app.Get(graphQLPath, controller.Execute)
+	app.Post(graphQLPath, controller.Execute)
+}
+
+// Execute answers with a GraphQL response rather than an envelope, with
+// errors listed in it and a 200 once the request could be read. GET takes
+// query=, operationName= and variables= and only runs queries; POST takes a
+// JSON request or, as application/graphql, the bare query. The response is
+// application/graphql-response+json or application/json, as Accept prefers.
+func (controller *GraphQLController) Execute(ctx *fiber.Ctx) error {
+	ctx.Vary(fiber.HeaderAccept)
+	mime := ctx.Accepts(graphQLResponseTypes...)
+	if mime == "" {
+		return fiber.NewError(fiber.StatusNotAcceptable, "acceptable response types are "+strings.Join(graphQLResponseTypes, ", "))
+	}
+
+	var request graph.Request
+	post := ctx.Method() == fiber.MethodPost
+
+	switch {
+	case !post:
+		request.Query = ctx.Query("query")
+		request.OperationName = ctx.Query("operationName")
+		if raw := ctx.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &request.Variables); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "variables must be a JSON object")
+			}
+		}
+	case strings.HasPrefix(ctx.Get(fiber.HeaderContentType), mimeApplicationGraphQL):
+		request.Query = string(ctx.Body())
+	default:
+		if err := json.Unmarshal(ctx.Body(), &request); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "body must be a GraphQL request: "+err.Error())
+		}
+	}
+
+	if strings.TrimSpace(request.Query) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "query is required")
+	}
+
+	result := controller.Server.Execute(ctx.UserContext(), request, post)
+	if err := ctx.JSON(result); err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, mime+"; charset=utf-8")
+	return nil
+}
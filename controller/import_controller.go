@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/importer"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+type ImportController struct {
+	ImportService service.ImportService
+	AdminToken    string
+}
+
+func NewImportController(importService *service.ImportService, adminToken string) ImportController {
+	return ImportController{
+		ImportService: *importService,
+		AdminToken:    adminToken,
+	}
+}
+
+func (controller *ImportController) SetupRoutes(app *fiber.App) {
+	admin := adminOnly(controller.AdminToken)
+	app.Post("/import/markdown", admin, controller.ImportMarkdown)
+	app.Post("/import/wxr", admin, controller.ImportWXR)
+}
+
+// ImportMarkdown takes a zip, tar or tar.gz archive in the "file" field and
+// only writes anything when called with dry_run=false.
+func (controller *ImportController) ImportMarkdown(ctx *fiber.Ctx) error {
+	dryRun, err := importDryRun(ctx)
+	if err != nil {
+		return err
+	}
+
+	header, formErr := ctx.FormFile("file")
+	if formErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "multipart field \"file\" is required")
+	}
+
+	file, openErr := header.Open()
+	util.ReturnErrorIfNeeded(openErr)
+	defer file.Close()
+
+	files, readErr := importer.ReadArchive(file, header.Size, importer.MarkdownExtensions...)
+	if readErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, readErr.Error())
+	}
+
+	report := controller.ImportService.ImportMarkdown(files, dryRun)
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       report,
+	})
+}
+
+// ImportWXR takes a WordPress export file in the "file" field. Like the
+// Markdown import it is a dry run unless called with dry_run=false.
+func (controller *ImportController) ImportWXR(ctx *fiber.Ctx) error {
+	dryRun, err := importDryRun(ctx)
+	if err != nil {
+		return err
+	}
+
+	header, formErr := ctx.FormFile("file")
+	if formErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "multipart field \"file\" is required")
+	}
+
+	file, openErr := header.Open()
+	util.ReturnErrorIfNeeded(openErr)
+	defer file.Close()
+
+	export, readErr := importer.ReadWXR(file)
+	if readErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, readErr.Error())
+	}
+
+	report := controller.ImportService.ImportWXR(export, dryRun)
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       report,
+	})
+}
+
+func importDryRun(ctx *fiber.Ctx) (bool, error) {
+	value := ctx.Query("dry_run")
+	if value == "" {
+		return true, nil
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusBadRequest, "dry_run must be true or false")
+	}
+	return dryRun, nil
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+type MediaController struct {
+	MediaService  service.MediaService
+	MaxUploadSize int64
+}
+
+func NewMediaController(mediaService *service.MediaService, maxUploadSize int64) MediaController {
+	return MediaController{
+		MediaService:  *mediaService,
+		MaxUploadSize: maxUploadSize,
+	}
+}
+
+func (controller *MediaController) SetupRoutes(app *fiber.App) {
+	app.Post("/media", controller.Upload)
+	app.Get("/media/:id", controller.FindOne)
+	app.Get("/media/:id/content", controller.Content)
+	app.Get("/media/:id/variants/:size", controller.Variant)
+	app.Get("/article/:id/attachments", controller.ListAttachments)
+	app.Post("/article/:id/attachments", controller.Attach)
+	app.Delete("/article/:id/attachments/:mediaId", controller.Detach)
+}
+
+func (controller *MediaController) Upload(ctx *fiber.Ctx) error {
+	header, formErr := ctx.FormFile("file")
+	if formErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "multipart field \"file\" is required")
+	}
+
+	if header.Size > controller.MaxUploadSize {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file exceeds "+strconv.FormatInt(controller.MaxUploadSize, 10)+" bytes")
+	}
+
+	file, openErr := header.Open()
+	util.ReturnErrorIfNeeded(openErr)
+	defer file.Close()
+
+	contentType, sniffErr := storage.Sniff(file)
+	util.ReturnErrorIfNeeded(sniffErr)
+
+	if !storage.Allowed(contentType) {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "unsupported media type "+contentType)
+	}
+
+	mediaItem := controller.MediaService.Upload(header.Filename, contentType, file)
+
+	ctx.Location("/media/" + strconv.FormatInt(mediaItem.ID, 10))
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
+		StatusCode: fiber.StatusCreated,
+		Data:       mediaItem,
+	})
+}
+
+func (controller *MediaController) FindOne(ctx *fiber.Ctx) error {
+	mediaItem := controller.MediaService.FindOne(ctx.Params("id"))
+	if mediaItem == nil {
+		return fiber.NewError(fiber.StatusNotFound, "media not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       mediaItem,
+	})
+}
+
+func (controller *MediaController) Content(ctx *fiber.Ctx) error {
+	mediaItem, content := controller.MediaService.Open(ctx.Params("id"))
+	if mediaItem == nil {
+		return fiber.NewError(fiber.StatusNotFound, "media not found")
+	}
+
+	return serveMedia(ctx, mediaItem, content)
+}
+
+func (controller *MediaController) Variant(ctx *fiber.Ctx) error {
+	mediaItem, content := controller.MediaService.OpenVariant(ctx.Params("id"), ctx.Params("size"))
+	if mediaItem == nil {
+		return fiber.NewError(fiber.StatusNotFound, "image variant not found")
+	}
+
+	return serveMedia(ctx, mediaItem, content)
+}
+
+func (controller *MediaController) ListAttachments(ctx *fiber.Ctx) error {
+	mediaItems := controller.MediaService.ListByArticle(ctx.Params("id"))
+	if mediaItems == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       mediaItems,
+	})
+}
+
+func (controller *MediaController) Attach(ctx *fiber.Ctx) error {
+	var request *model.AttachmentRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	mediaItem := controller.MediaService.Attach(ctx.Params("id"), request)
+	if mediaItem == nil {
+		return fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
+		StatusCode: fiber.StatusCreated,
+		Data:       mediaItem,
+	})
+}
+
+func (controller *MediaController) Detach(ctx *fiber.Ctx) error {
+	controller.MediaService.Detach(ctx.Params("id"), ctx.Params("mediaId"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       "Attachment removed",
+	})
+}
+
+// serveMedia streams stored content, honouring a single byte range. Requests
+// for several ranges get the whole file.
+func serveMedia(ctx *fiber.Ctx, mediaItem *model.MediaResponse, content io.ReadSeekCloser) error {
+	etag := `"` + mediaItem.SHA256 + `"`
+	ctx.Set(fiber.HeaderCacheControl, mediaCacheControl)
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	if notModified(ctx, etag, mediaItem.CreatedAt) {
+		content.Close()
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, mediaItem.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, "inline; filename="+strconv.Quote(mediaItem.Filename))
+
+	if ctx.Get(fiber.HeaderRange) == "" || !rangeApplies(ctx, etag) {
+		return ctx.Status(fiber.StatusOK).SendStream(content, int(mediaItem.Size))
+	}
+
+	byteRange, rangeErr := ctx.Range(int(mediaItem.Size))
+	if rangeErr == fiber.ErrRangeUnsatisfiable {
+		content.Close()
+		ctx.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(mediaItem.Size, 10))
+		return fiber.NewError(fiber.StatusRequestedRangeNotSatisfiable, "requested range not satisfiable")
+	}
+
+	if rangeErr != nil || byteRange.Type != "bytes" || len(byteRange.Ranges) != 1 {
+		return ctx.Status(fiber.StatusOK).SendStream(content, int(mediaItem.Size))
+	}
+
+	start, end := byteRange.Ranges[0].Start, byteRange.Ranges[0].End
+	if _, seekErr := content.Seek(int64(start), io.SeekStart); seekErr != nil {
+		content.Close()
+		return seekErr
+	}
+
+	ctx.Set(fiber.HeaderContentRange, "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(end)+"/"+strconv.FormatInt(mediaItem.Size, 10))
+	length := end - start + 1
+	return ctx.Status(fiber.StatusPartialContent).SendStream(readCloser{io.LimitReader(content, int64(length)), content}, length)
+}
+
+// rangeApplies evaluates If-Range, which only allows a partial response while
+// the client's copy is still current.
+func rangeApplies(ctx *fiber.Ctx, etag string) bool {
+	ifRange := ctx.Get(fiber.HeaderIfRange)
+	return ifRange == "" || ifRange == etag
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	mimeApplicationMsgPack  = "application/msgpack"
+	mimeApplicationXMsgPack = "application/x-msgpack"
+	mimeTextXML             = "text/xml"
+)
+
+// responseTypes are offered in this order, so clients that accept anything
+// get JSON.
+var responseTypes = []string{fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML, mimeTextXML, mimeApplicationMsgPack, mimeApplicationXMsgPack}
+
+// requestTypes are the body types parseBody decodes besides forms.
+var requestTypes = []string{fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML, mimeTextXML, mimeApplicationMsgPack, mimeApplicationXMsgPack}
+
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// Render writes a response envelope as JSON, XML or MessagePack, whichever
+// the Accept header prefers. XML and MessagePack are converted from the JSON
+// encoding, so all three carry the same fields, names and projections. A
+// client that accepts none of them gets a 406, and error envelopes fall back
+// to JSON so the reason stays readable.
+func Render(ctx *fiber.Ctx, status int, envelope interface{}) error {
+	ctx.Vary(fiber.HeaderAccept)
+
+	mime := ctx.Accepts(responseTypes...)
+	if mime == "" {
+		if _, ok := envelope.(model.ErrorResponse); !ok {
+			return fiber.NewError(fiber.StatusNotAcceptable, "acceptable response types are "+strings.Join(responseTypes, ", "))
+		}
+		mime = fiber.MIMEApplicationJSON
+	}
+
+	if mime == fiber.MIMEApplicationJSON {
+		return ctx.Status(status).JSON(envelope)
+	}
+
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	switch mime {
+	case fiber.MIMEApplicationXML, mimeTextXML:
+		body, err = jsonToXML(encoded, "response")
+		mime += "; charset=utf-8"
+	default:
+		body, err = jsonToMsgPack(encoded)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, mime)
+	return ctx.Status(status).Send(body)
+}
+
+// RejectUnacceptable answers writes with a 406 before the handler runs when
+// the Accept header rules out every envelope type, so the 406 never follows
+// a change that was already made. GraphQL answers with types of its own and
+// checks Accept itself.
+func RejectUnacceptable() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		method := ctx.Method()
+		if ctx.Path() == graphQLPath {
+			return ctx.Next()
+		}
+		if method != fiber.MethodGet && method != fiber.MethodHead && ctx.Accepts(responseTypes...) == "" {
+			return fiber.NewError(fiber.StatusNotAcceptable, "acceptable response types are "+strings.Join(responseTypes, ", "))
+		}
+		return ctx.Next()
+	}
+}
+
+// parseBody decodes a request body according to its Content-Type. XML is
+// read through the model's xml tags and MessagePack through its json tags;
+// JSON and forms are left to BodyParser. Other types get a 415.
+func parseBody(ctx *fiber.Ctx, out interface{}) error {
+	mime := strings.ToLower(strings.TrimSpace(strings.Split(ctx.Get(fiber.HeaderContentType), ";")[0]))
+
+	switch mime {
+	case "", fiber.MIMEApplicationJSON, fiber.MIMEApplicationForm, fiber.MIMEMultipartForm:
+		return ctx.BodyParser(out)
+	case fiber.MIMEApplicationXML, mimeTextXML:
+		return xml.Unmarshal(ctx.Body(), out)
+	case mimeApplicationMsgPack, mimeApplicationXMsgPack:
+		decoder := msgpack.NewDecoder(bytes.NewReader(ctx.Body()))
+		decoder.SetCustomStructTag("json")
+		return decoder.Decode(out)
+	}
+
+	return fiber.NewError(fiber.StatusUnsupportedMediaType, "supported request types are "+strings.Join(requestTypes, ", "))
+}
+
+// jsonToXML maps objects to elements named after their keys and arrays to
+// repeated <item> elements. Keys that are not valid element names become
+// <entry key="...">.
+func jsonToXML(encoded []byte, root string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := encodeXMLValue(encoder, decoder, xml.StartElement{Name: xml.Name{Local: root}}); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeXMLValue(encoder *xml.Encoder, decoder *json.Decoder, start xml.StartElement) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		for decoder.More() {
+			element := xml.StartElement{Name: xml.Name{Local: "item"}}
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				element = xmlElement(key.(string))
+			}
+
+			if err := encodeXMLValue(encoder, decoder, element); err != nil {
+				return err
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = encoder.EncodeToken(xml.CharData(value))
+	case json.Number:
+		err = encoder.EncodeToken(xml.CharData(value.String()))
+	case bool:
+		err = encoder.EncodeToken(xml.CharData(strconv.FormatBool(value)))
+	}
+	if err != nil {
+		return err
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+func xmlElement(key string) xml.StartElement {
+	if xmlNamePattern.MatchString(key) && !strings.HasPrefix(strings.ToLower(key), "xml") {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+// jsonToMsgPack sorts map keys so that equal responses encode to equal
+// bytes and keep their ETag.
+func jsonToMsgPack(encoded []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(msgPackNumbers(value)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// msgPackNumbers turns the decoded JSON numbers into integers where they
+// are whole and into floats otherwise.
+func msgPackNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = msgPackNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = msgPackNumbers(item)
+		}
+	}
+	return value
+}
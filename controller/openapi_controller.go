@@ -0,0 +1,70 @@
+package controller
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/openapi"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+type OpenAPIController struct {
+	Document []byte
+}
+
+func NewOpenAPIController(document *openapi.Document) OpenAPIController {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		panic(err)
+	}
+
+	return OpenAPIController{
+		Document: encoded,
+	}
+}
+
+func (controller *OpenAPIController) SetupRoutes(app *fiber.App) {
+	app.Get("/openapi.json", controller.Spec)
+	app.Get("/docs", controller.Docs)
+}
+
+func (controller *OpenAPIController) Spec(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return ctx.Send(controller.Document)
+}
+
+// Docs serves a page that reads /openapi.json and lists the operations by
+// tag, each with a form to try it against this server.
+func (controller *OpenAPIController) Docs(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.Send(docsPage)
+}
+
+// CheckRoutes returns an error naming every route registered on app that
+// the document does not describe. HEAD routes come with their GET route and
+// middleware added with Use sits on "/", so both are skipped.
+func CheckRoutes(app *fiber.App, document *openapi.Document) error {
+	var missing []string
+	for _, routes := range app.Stack() {
+		for _, route := range routes {
+			if route.Method == fiber.MethodHead || route.Path == "/" {
+				continue
+			}
+			if !document.Has(route.Method, route.Path) {
+				missing = append(missing, route.Method+" "+route.Path)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
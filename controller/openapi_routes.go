@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/graph"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/openapi"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+)
+
+const mimeNDJSON = "application/x-ndjson"
+
+var (
+	articleListQuery = []openapi.Param{
+		{Name: "title", Description: "Only articles whose title contains this text."},
+		{Name: "fields", Description: "Comma-separated fields to return: " + strings.Join(model.ArticleFields, ", ") + "."},
+		{Name: "expand", Description: "Comma-separated relationships to embed: " + strings.Join(model.ArticleExpansions, ", ") + ". author and tags are refused as not supported, since articles have neither yet."},
+		{Name: "include_content", Type: "boolean", Description: "Include content, content_html and toc, which listings leave out by default."},
+		{Name: "format", Enum: []string{"json", "csv"}, Description: "Response format. Overrides the Accept header."},
+	}
+
+	articleQuery = []openapi.Param{
+		{Name: "fields", Description: "Comma-separated fields to return: " + strings.Join(model.ArticleFields, ", ") + "."},
+		{Name: "expand", Description: "Comma-separated relationships to embed: " + strings.Join(model.ArticleExpansions, ", ") + ". author and tags are refused as not supported, since articles have neither yet."},
+	}
+
+	categoryQuery = []openapi.Param{
+		{Name: "fields", Description: "Comma-separated fields to return: " + strings.Join(model.CategoryFields, ", ") + "."},
+		{Name: "expand", Description: "Comma-separated relationships to embed: " + strings.Join(model.CategoryExpansions, ", ") + "."},
+		{Name: "with_stats", Type: "boolean", Description: "Same as expand=stats."},
+	}
+
+	categoryListQuery = append(append([]openapi.Param{}, categoryQuery...),
+		openapi.Param{Name: "format", Enum: []string{"json", "csv"}, Description: "Response format. Overrides the Accept header."})
+
+	bulkQuery = []openapi.Param{
+		{Name: "mode", Enum: []string{model.BulkModeAtomic, model.BulkModeBestEffort}, Description: "atomic (the default) rolls every item back when one fails; best_effort keeps the rest and answers 207."},
+	}
+
+	importQuery = []openapi.Param{
+		{Name: "dry_run", Type: "boolean", Description: "Report what would be imported without writing anything. Defaults to true."},
+	}
+
+	feedTypes       = []string{"application/rss+xml", "application/atom+xml", "application/feed+json"}
+	imageTypes      = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+	commentStatuses = []string{entity.CommentStatusPending, entity.CommentStatusApproved, entity.CommentStatusRejected, entity.CommentStatusSpam}
+)
+
+// NewOpenAPIDocument describes every route the controllers register. Main
+// checks it against the routes on the app at startup, so a route added
+// without an entry here stops the server from starting.
+func NewOpenAPIDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "backendtest",
+		Version:     "1.0.0",
+		Description: "Articles, categories, comments and media. Envelopes are negotiated from the Accept header as JSON, XML or MessagePack.",
+	}, model.SuccessResponse{}, model.ErrorResponse{}, responseTypes...)
+
+	for _, route := range articleRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range categoryRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range commentRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range mediaRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range webhookRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range siteRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range graphQLRoutes() {
+		doc.Add(route)
+	}
+	for _, route := range adminRoutes() {
+		doc.Add(route)
+	}
+
+	return doc
+}
+
+func articleRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "listArticles", Method: fiber.MethodGet, Path: "/article", Tag: "Articles", Summary: "List articles", Query: articleListQuery, Data: []model.ArticleResponse{}, Alternatives: []string{mimeTextCSV}},
+		{ID: "createArticle", Method: fiber.MethodPost, Path: "/article", Tag: "Articles", Summary: "Create an article", Body: model.ArticleCreateRequest{}, BodyTypes: requestTypes, Status: fiber.StatusCreated, Data: model.ArticleResponse{}},
+		{ID: "getArticle", Method: fiber.MethodGet, Path: "/article/:id", Tag: "Articles", Summary: "Get an article", Query: articleQuery, Data: model.ArticleResponse{}},
+		{ID: "getArticleBySlug", Method: fiber.MethodGet, Path: "/article/slug/:slug", Tag: "Articles", Summary: "Get an article by slug", Query: articleQuery, Data: model.ArticleResponse{}},
+		{ID: "updateArticle", Method: fiber.MethodPut, Path: "/article/:id", Tag: "Articles", Summary: "Update an article", Description: "A status, content_format or featured_image_id left out keeps the current one.", Body: model.ArticleUpdateRequest{}, BodyTypes: requestTypes, Data: model.ArticleResponse{}},
+		{ID: "softDeleteArticle", Method: fiber.MethodDelete, Path: "/article/:id", Tag: "Articles", Summary: "Move an article to the trash", Data: ""},
+		{ID: "listDeletedArticles", Method: fiber.MethodGet, Path: "/article/deleted", Tag: "Articles", Summary: "List trashed articles", Query: articleListQuery[1:4], Data: []model.ArticleResponse{}},
+		{ID: "deleteArticle", Method: fiber.MethodDelete, Path: "/article/deleted/:id", Tag: "Articles", Summary: "Delete a trashed article for good", Data: ""},
+		{ID: "moveArticles", Method: fiber.MethodPost, Path: "/article/move", Tag: "Articles", Summary: "Move articles to another category", Body: model.ArticleMoveRequest{}, Data: model.ArticleMoveResponse{}},
+		{ID: "createArticles", Method: fiber.MethodPost, Path: "/article/bulk", Tag: "Articles", Summary: "Create articles in bulk", Description: "Takes a JSON array or one JSON object per line.", Query: bulkQuery, Body: []model.ArticleCreateRequest{}, BodyTypes: []string{fiber.MIMEApplicationJSON, mimeNDJSON}, Data: model.BulkResponse{}},
+		{ID: "softDeleteArticles", Method: fiber.MethodDelete, Path: "/article/bulk", Tag: "Articles", Summary: "Move articles to the trash in bulk", Query: bulkQuery, Body: model.BulkDeleteRequest{}, Data: model.BulkResponse{}},
+		{ID: "deleteArticles", Method: fiber.MethodDelete, Path: "/article/deleted/bulk", Tag: "Articles", Summary: "Delete trashed articles for good in bulk", Query: bulkQuery, Body: model.BulkDeleteRequest{}, Data: model.BulkResponse{}},
+	}
+}
+
+func categoryRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "listCategories", Method: fiber.MethodGet, Path: "/category", Tag: "Categories", Summary: "List categories", Query: categoryListQuery, Data: []model.CategoryResponse{}, Alternatives: []string{mimeTextCSV}},
+		{ID: "createCategory", Method: fiber.MethodPost, Path: "/category", Tag: "Categories", Summary: "Create a category", Body: model.CategoryCreateRequest{}, Status: fiber.StatusCreated, Data: model.CategoryResponse{}},
+		{ID: "getCategory", Method: fiber.MethodGet, Path: "/category/:id", Tag: "Categories", Summary: "Get a category", Query: categoryQuery, Data: model.CategoryResponse{}},
+		{ID: "getCategoryBySlug", Method: fiber.MethodGet, Path: "/category/slug/:slug", Tag: "Categories", Summary: "Get a category by slug", Description: "Old slugs redirect to the current one.", Data: model.CategoryResponse{}},
+		{ID: "updateCategory", Method: fiber.MethodPut, Path: "/category/:id", Tag: "Categories", Summary: "Update a category", Body: model.CategoryUpdateRequest{}, Data: model.CategoryResponse{}},
+		{ID: "softDeleteCategory", Method: fiber.MethodDelete, Path: "/category/:id", Tag: "Categories", Summary: "Move a category to the trash", Data: ""},
+		{ID: "listDeletedCategories", Method: fiber.MethodGet, Path: "/category/deleted", Tag: "Categories", Summary: "List trashed categories", Data: []model.CategoryResponse{}},
+		{ID: "deleteCategory", Method: fiber.MethodDelete, Path: "/category/deleted/:id", Tag: "Categories", Summary: "Delete a trashed category for good", Data: ""},
+		{ID: "mergeCategory", Method: fiber.MethodPost, Path: "/category/:id/merge", Tag: "Categories", Summary: "Merge a category into another", Body: model.CategoryMergeRequest{}, Data: model.CategoryResponse{}},
+	}
+}
+
+func commentRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "createComment", Method: fiber.MethodPost, Path: "/article/:id/comments", Tag: "Comments", Summary: "Comment on an article", Body: model.CommentCreateRequest{}, Status: fiber.StatusCreated, Data: model.CommentResponse{}},
+		{ID: "listComments", Method: fiber.MethodGet, Path: "/article/:id/comments", Tag: "Comments", Summary: "List the approved comments of an article", Data: []model.CommentResponse{}},
+		{ID: "listCommentsForModeration", Method: fiber.MethodGet, Path: "/comments/moderation", Tag: "Comments", Summary: "List comments awaiting moderation", Query: []openapi.Param{{Name: "status", Enum: commentStatuses, Description: "Defaults to pending."}}, Data: []model.CommentResponse{}, Admin: true},
+		{ID: "moderateComment", Method: fiber.MethodPut, Path: "/comments/:id/status", Tag: "Comments", Summary: "Set the moderation status of a comment", Body: model.CommentModerateRequest{}, Data: model.CommentResponse{}, Admin: true},
+		{ID: "softDeleteComment", Method: fiber.MethodDelete, Path: "/comments/:id", Tag: "Comments", Summary: "Move a comment to the trash", Data: "", Admin: true},
+		{ID: "listDeletedComments", Method: fiber.MethodGet, Path: "/comments/deleted", Tag: "Comments", Summary: "List trashed comments", Data: []model.CommentResponse{}, Admin: true},
+		{ID: "deleteComment", Method: fiber.MethodDelete, Path: "/comments/deleted/:id", Tag: "Comments", Summary: "Delete a trashed comment for good", Data: "", Admin: true},
+	}
+}
+
+func mediaRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "uploadMedia", Method: fiber.MethodPost, Path: "/media", Tag: "Media", Summary: "Upload a file", Description: "Accepted types: " + strings.Join(storage.AllowedContentTypes, ", ") + ".", Upload: true, Status: fiber.StatusCreated, Data: model.MediaResponse{}},
+		{ID: "getMedia", Method: fiber.MethodGet, Path: "/media/:id", Tag: "Media", Summary: "Get a file's metadata", Data: model.MediaResponse{}},
+		{ID: "getMediaContent", Method: fiber.MethodGet, Path: "/media/:id/content", Tag: "Media", Summary: "Download a file", Produces: storage.AllowedContentTypes},
+		{ID: "getMediaVariant", Method: fiber.MethodGet, Path: "/media/:id/variants/:size", Tag: "Media", Summary: "Download a resized image", Produces: imageTypes},
+		{ID: "listAttachments", Method: fiber.MethodGet, Path: "/article/:id/attachments", Tag: "Media", Summary: "List the files attached to an article", Data: []model.MediaResponse{}},
+		{ID: "attachMedia", Method: fiber.MethodPost, Path: "/article/:id/attachments", Tag: "Media", Summary: "Attach a file to an article", Body: model.AttachmentRequest{}, Status: fiber.StatusCreated, Data: model.MediaResponse{}},
+		{ID: "detachMedia", Method: fiber.MethodDelete, Path: "/article/:id/attachments/:mediaId", Tag: "Media", Summary: "Detach a file from an article", Data: ""},
+	}
+}
+
+func webhookRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "createWebhook", Method: fiber.MethodPost, Path: "/webhooks", Tag: "Webhooks", Summary: "Register a webhook", Body: model.WebhookCreateRequest{}, Status: fiber.StatusCreated, Data: model.WebhookResponse{}, Admin: true},
+		{ID: "listWebhooks", Method: fiber.MethodGet, Path: "/webhooks", Tag: "Webhooks", Summary: "List webhooks", Data: []model.WebhookResponse{}, Admin: true},
+		{ID: "getWebhook", Method: fiber.MethodGet, Path: "/webhooks/:id", Tag: "Webhooks", Summary: "Get a webhook", Data: model.WebhookResponse{}, Admin: true},
+		{ID: "updateWebhook", Method: fiber.MethodPut, Path: "/webhooks/:id", Tag: "Webhooks", Summary: "Update a webhook", Body: model.WebhookUpdateRequest{}, Data: model.WebhookResponse{}, Admin: true},
+		{ID: "deleteWebhook", Method: fiber.MethodDelete, Path: "/webhooks/:id", Tag: "Webhooks", Summary: "Delete a webhook", Data: "", Admin: true},
+		{ID: "listWebhookDeliveries", Method: fiber.MethodGet, Path: "/webhooks/:id/deliveries", Tag: "Webhooks", Summary: "List a webhook's deliveries", Data: []model.WebhookDeliveryResponse{}, Admin: true},
+		{ID: "getWebhookDelivery", Method: fiber.MethodGet, Path: "/webhooks/:id/deliveries/:deliveryId", Tag: "Webhooks", Summary: "Get a delivery with its attempts", Data: model.WebhookDeliveryResponse{}, Admin: true},
+		{ID: "redeliverWebhook", Method: fiber.MethodPost, Path: "/webhooks/:id/deliveries/:deliveryId/redeliver", Tag: "Webhooks", Summary: "Send a delivery again", Status: fiber.StatusAccepted, Data: model.WebhookDeliveryResponse{}, Admin: true},
+	}
+}
+
+func siteRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "getRSSFeed", Method: fiber.MethodGet, Path: "/feed.rss", Tag: "Feeds", Summary: "RSS feed of recent articles", Produces: feedTypes[:1]},
+		{ID: "getAtomFeed", Method: fiber.MethodGet, Path: "/feed.atom", Tag: "Feeds", Summary: "Atom feed of recent articles", Produces: feedTypes[1:2]},
+		{ID: "getJSONFeed", Method: fiber.MethodGet, Path: "/feed.json", Tag: "Feeds", Summary: "JSON Feed of recent articles", Produces: feedTypes[2:]},
+		{ID: "getCategoryRSSFeed", Method: fiber.MethodGet, Path: "/category/:slug/feed.rss", Tag: "Feeds", Summary: "RSS feed of a category", Produces: feedTypes[:1]},
+		{ID: "getCategoryAtomFeed", Method: fiber.MethodGet, Path: "/category/:slug/feed.atom", Tag: "Feeds", Summary: "Atom feed of a category", Produces: feedTypes[1:2]},
+		{ID: "getCategoryJSONFeed", Method: fiber.MethodGet, Path: "/category/:slug/feed.json", Tag: "Feeds", Summary: "JSON Feed of a category", Produces: feedTypes[2:]},
+		{ID: "getSitemapIndex", Method: fiber.MethodGet, Path: "/sitemap.xml", Tag: "Feeds", Summary: "Sitemap index", Produces: []string{fiber.MIMEApplicationXML}},
+		{ID: "getSitemapPage", Method: fiber.MethodGet, Path: "/sitemap-:page.xml", Tag: "Feeds", Summary: "One page of the sitemap", Produces: []string{fiber.MIMEApplicationXML}},
+		{ID: "streamEvents", Method: fiber.MethodGet, Path: "/events/stream", Tag: "Events", Summary: "Stream change events", Description: "Server-Sent Events. Resume with the Last-Event-ID header, or last_event_id= where headers cannot be set.", Query: []openapi.Param{
+			{Name: "types", Description: "Comma-separated event types, or wildcards such as category.*."},
+			{Name: "category_id", Type: "integer", Description: "Only events about this category."},
+			{Name: "last_event_id", Description: "Same as the Last-Event-ID header."},
+		}, Headers: []openapi.Param{{Name: "Last-Event-ID", Description: "ID of the last event received."}}, Produces: []string{"text/event-stream"}},
+		{ID: "getStats", Method: fiber.MethodGet, Path: "/stats", Tag: "Stats", Summary: "Article and category statistics", Query: []openapi.Param{
+			{Name: "from", Description: "First day of the daily series, as YYYY-MM-DD."},
+			{Name: "to", Description: "Last day of the daily series, as YYYY-MM-DD."},
+		}, Data: model.StatsResponse{}},
+		{ID: "getCacheStats", Method: fiber.MethodGet, Path: "/cache/stats", Tag: "Stats", Summary: "Repository cache counters", Data: cache.Stats{}},
+		{ID: "getOpenAPIDocument", Method: fiber.MethodGet, Path: "/openapi.json", Tag: "Docs", Summary: "This document", Produces: []string{fiber.MIMEApplicationJSON}},
+		{ID: "getDocs", Method: fiber.MethodGet, Path: "/docs", Tag: "Docs", Summary: "Interactive API documentation", Produces: []string{fiber.MIMETextHTML}},
+	}
+}
+
+func graphQLRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "queryGraphQL", Method: fiber.MethodGet, Path: "/graphql", Tag: "GraphQL", Summary: "Run a GraphQL query", Description: "Mutations have to be sent with POST.", Query: []openapi.Param{
+			{Name: "query", Required: true, Description: "The GraphQL document."},
+			{Name: "operationName", Description: "The operation to run when the document has several."},
+			{Name: "variables", Description: "Variables as a JSON object."},
+		}, Produces: graphQLResponseTypes},
+		{ID: "executeGraphQL", Method: fiber.MethodPost, Path: "/graphql", Tag: "GraphQL", Summary: "Run a GraphQL query or mutation", Description: "application/graphql takes the bare document.", Body: graph.Request{}, BodyTypes: []string{fiber.MIMEApplicationJSON, mimeApplicationGraphQL}, Produces: graphQLResponseTypes},
+	}
+}
+
+func adminRoutes() []openapi.Route {
+	return []openapi.Route{
+		{ID: "importMarkdown", Method: fiber.MethodPost, Path: "/import/markdown", Tag: "Import", Summary: "Import Markdown posts", Description: "Takes a zip, tar or tar.gz archive of Markdown files with front matter.", Query: importQuery, Upload: true, Data: model.ImportReport{}, Admin: true},
+		{ID: "importWXR", Method: fiber.MethodPost, Path: "/import/wxr", Tag: "Import", Summary: "Import a WordPress export", Query: importQuery, Upload: true, Data: model.ImportReport{}, Admin: true},
+		{ID: "exportArchive", Method: fiber.MethodGet, Path: "/export", Tag: "Import", Summary: "Download a full export archive", Produces: []string{"application/zip"}, Admin: true},
+	}
+}
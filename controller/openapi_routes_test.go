@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+// TestEveryRouteIsDocumented registers the routes of every controller, the
+// way main does, and fails for any the OpenAPI document does not describe.
+// No handler runs, so the services can be left nil.
+func TestEveryRouteIsDocumented(t *testing.T) {
+	var (
+		articleService     service.ArticleService
+		categoryService    service.CategoryService
+		commentService     service.CommentService
+		mediaService       service.MediaService
+		importService      service.ImportService
+		backupService      service.BackupService
+		statsService       service.StatsService
+		feedService        service.FeedService
+		sitemapService     service.SitemapService
+		webhookService     service.WebhookService
+		eventStreamService service.EventStreamService
+	)
+
+	document := NewOpenAPIDocument()
+	articleController := NewArticleController(&articleService)
+	categoryController := NewCategoryController(&categoryService)
+	commentController := NewCommentController(&commentService, "")
+	mediaController := NewMediaController(&mediaService, 0)
+	importController := NewImportController(&importService, "")
+	exportController := NewExportController(&backupService, "")
+	statsController := NewStatsController(&statsService)
+	feedController := NewFeedController(&feedService, &categoryService, "", "")
+	sitemapController := NewSitemapController(&sitemapService, "")
+	cacheController := NewCacheController(cache.NewLRU(1, 0), &cache.Counters{})
+	webhookController := NewWebhookController(&webhookService, "")
+	eventStreamController := NewEventStreamController(&eventStreamService)
+	graphQLController := NewGraphQLController(nil)
+	openAPIController := NewOpenAPIController(document)
+
+	app := fiber.New()
+	articleController.SetupRoutes(app)
+	categoryController.SetupRoutes(app)
+	commentController.SetupRoutes(app)
+	mediaController.SetupRoutes(app)
+	importController.SetupRoutes(app)
+	exportController.SetupRoutes(app)
+	statsController.SetupRoutes(app)
+	feedController.SetupRoutes(app)
+	sitemapController.SetupRoutes(app)
+	cacheController.SetupRoutes(app)
+	webhookController.SetupRoutes(app)
+	eventStreamController.SetupRoutes(app)
+	graphQLController.SetupRoutes(app)
+	openAPIController.SetupRoutes(app)
+
+	if err := CheckRoutes(app, document); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+// parseSelection reads the fields= and expand= query parameters. Without
+// fields= the defaults are selected and responses keep their full shape.
+func parseSelection(ctx *fiber.Ctx, allowedFields []string, allowedExpansions []string, defaults []string) *model.Selection {
+	selection := model.Selection{Fields: defaults}
+
+	if raw := ctx.Query("fields"); raw != "" {
+		selection.Fields = splitList(raw)
+		selection.Sparse = true
+		for _, field := range selection.Fields {
+			if !util.Contains(allowedFields, field) {
+				panic(fiber.NewError(fiber.StatusBadRequest, "unknown field: "+field))
+			}
+		}
+	}
+
+	if raw := ctx.Query("expand"); raw != "" {
+		selection.Expand = splitList(raw)
+		for _, expansion := range selection.Expand {
+			if reason, ok := model.UnsupportedExpansions[expansion]; ok {
+				panic(fiber.NewError(fiber.StatusBadRequest, "expansion not supported: "+expansion+", "+reason))
+			}
+			if !util.Contains(allowedExpansions, expansion) {
+				panic(fiber.NewError(fiber.StatusBadRequest, "unsupported expansion: "+expansion))
+			}
+		}
+	}
+
+	return &selection
+}
+
+func articleSelection(ctx *fiber.Ctx, list bool) *model.Selection {
+	defaults := model.ArticleFields
+	if list && ctx.Query("include_content") != "true" {
+		defaults = nil
+		for _, field := range model.ArticleFields {
+			if !util.Contains(model.ArticleContentFields, field) {
+				defaults = append(defaults, field)
+			}
+		}
+	}
+
+	return parseSelection(ctx, model.ArticleFields, model.ArticleExpansions, defaults)
+}
+
+func categorySelection(ctx *fiber.Ctx) *model.Selection {
+	selection := parseSelection(ctx, model.CategoryFields, model.CategoryExpansions, model.CategoryFields)
+	if ctx.Query("with_stats") == "true" && !selection.Expands("stats") {
+		selection.Expand = append(selection.Expand, "stats")
+	}
+
+	return selection
+}
+
+// project trims the encoded response down to the requested fields plus the
+// expanded relationships, for a single object or a list of them.
+func project(data interface{}, selection *model.Selection) interface{} {
+	if !selection.Sparse {
+		return data
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+
+	if string(encoded) == "null" {
+		return data
+	}
+
+	keep := append(append([]string{}, selection.Fields...), selection.Expand...)
+	pick := func(object map[string]interface{}) map[string]interface{} {
+		picked := make(map[string]interface{}, len(keep))
+		for _, key := range keep {
+			if value, ok := object[key]; ok {
+				picked[key] = value
+			}
+		}
+		return picked
+	}
+
+	var list []map[string]interface{}
+	if json.Unmarshal(encoded, &list) == nil {
+		projected := make([]map[string]interface{}, 0, len(list))
+		for _, object := range list {
+			projected = append(projected, pick(object))
+		}
+		return projected
+	}
+
+	var object map[string]interface{}
+	if json.Unmarshal(encoded, &object) == nil && object != nil {
+		return pick(object)
+	}
+
+	return data
+}
+
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+type SitemapController struct {
+	SitemapService service.SitemapService
+	BaseURL        string
+}
+
+func NewSitemapController(sitemapService *service.SitemapService, baseURL string) SitemapController {
+	return SitemapController{
+		SitemapService: *sitemapService,
+		BaseURL:        baseURL,
+	}
+}
+
+func (controller *SitemapController) SetupRoutes(app *fiber.App) {
+	app.Get("/sitemap.xml", controller.Index)
+	app.Get("/sitemap-:page.xml", controller.Page)
+}
+
+func (controller *SitemapController) Index(ctx *fiber.Ctx) error {
+	return controller.send(ctx, 0)
+}
+
+func (controller *SitemapController) Page(ctx *fiber.Ctx) error {
+	page, err := ctx.ParamsInt("page")
+	if err != nil || page < 1 {
+		return fiber.NewError(fiber.StatusNotFound, "sitemap not found")
+	}
+
+	return controller.send(ctx, page)
+}
+
+func (controller *SitemapController) send(ctx *fiber.Ctx, page int) error {
+	baseURL := controller.BaseURL
+	if baseURL == "" {
+		baseURL = ctx.BaseURL()
+	}
+
+	body, modified := controller.SitemapService.Page(baseURL, page)
+	if body == nil {
+		return fiber.NewError(fiber.StatusNotFound, "sitemap not found")
+	}
+
+	addSurrogateKeys(ctx, cdn.ArticlesKey, cdn.CategoriesKey)
+	setLastModified(ctx, modified)
+
+	ctx.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	return ctx.Status(fiber.StatusOK).Send(body)
+}
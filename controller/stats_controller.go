@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+type StatsController struct {
+	StatsService service.StatsService
+}
+
+func NewStatsController(statsService *service.StatsService) StatsController {
+	return StatsController{
+		StatsService: *statsService,
+	}
+}
+
+func (controller *StatsController) SetupRoutes(app *fiber.App) {
+	app.Get("/stats", controller.Get)
+}
+
+func (controller *StatsController) Get(ctx *fiber.Ctx) error {
+	stats := controller.StatsService.Get(ctx.Query("from"), ctx.Query("to"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       stats,
+	})
+}
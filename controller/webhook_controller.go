@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+type WebhookController struct {
+	WebhookService service.WebhookService
+	AdminToken     string
+}
+
+func NewWebhookController(webhookService *service.WebhookService, adminToken string) WebhookController {
+	return WebhookController{
+		WebhookService: *webhookService,
+		AdminToken:     adminToken,
+	}
+}
+
+func (controller *WebhookController) SetupRoutes(app *fiber.App) {
+	admin := adminOnly(controller.AdminToken)
+	app.Post("/webhooks", admin, controller.Create)
+	app.Get("/webhooks", admin, controller.List)
+	app.Get("/webhooks/:id", admin, controller.FindOne)
+	app.Put("/webhooks/:id", admin, controller.Update)
+	app.Delete("/webhooks/:id", admin, controller.Delete)
+	app.Get("/webhooks/:id/deliveries", admin, controller.ListDeliveries)
+	app.Get("/webhooks/:id/deliveries/:deliveryId", admin, controller.FindDelivery)
+	app.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", admin, controller.Redeliver)
+}
+
+func (controller *WebhookController) Create(ctx *fiber.Ctx) error {
+	var request *model.WebhookCreateRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	webhook := controller.WebhookService.Create(request)
+
+	ctx.Location("/webhooks/" + strconv.FormatInt(webhook.ID, 10))
+	return Render(ctx, fiber.StatusCreated, model.SuccessResponse{
+		StatusCode: fiber.StatusCreated,
+		Data:       webhook,
+	})
+}
+
+func (controller *WebhookController) List(ctx *fiber.Ctx) error {
+	webhooks := controller.WebhookService.List()
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       webhooks,
+	})
+}
+
+func (controller *WebhookController) FindOne(ctx *fiber.Ctx) error {
+	webhook := controller.WebhookService.FindOne(ctx.Params("id"))
+	if webhook == nil {
+		return fiber.NewError(fiber.StatusNotFound, "webhook not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       webhook,
+	})
+}
+
+func (controller *WebhookController) Update(ctx *fiber.Ctx) error {
+	var request *model.WebhookUpdateRequest
+	parserErr := ctx.BodyParser(&request)
+	util.ReturnErrorIfNeeded(parserErr)
+
+	webhook := controller.WebhookService.Update(ctx.Params("id"), request)
+	if webhook == nil {
+		return fiber.NewError(fiber.StatusNotFound, "webhook not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       webhook,
+	})
+}
+
+func (controller *WebhookController) Delete(ctx *fiber.Ctx) error {
+	controller.WebhookService.Delete(ctx.Params("id"))
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       "Webhook deleted",
+	})
+}
+
+func (controller *WebhookController) ListDeliveries(ctx *fiber.Ctx) error {
+	deliveries := controller.WebhookService.ListDeliveries(ctx.Params("id"))
+	if deliveries == nil {
+		return fiber.NewError(fiber.StatusNotFound, "webhook not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       deliveries,
+	})
+}
+
+func (controller *WebhookController) FindDelivery(ctx *fiber.Ctx) error {
+	delivery := controller.WebhookService.FindDelivery(ctx.Params("id"), ctx.Params("deliveryId"))
+	if delivery == nil {
+		return fiber.NewError(fiber.StatusNotFound, "webhook delivery not found")
+	}
+
+	return Render(ctx, fiber.StatusOK, model.SuccessResponse{
+		StatusCode: fiber.StatusOK,
+		Data:       delivery,
+	})
+}
+
+func (controller *WebhookController) Redeliver(ctx *fiber.Ctx) error {
+	delivery := controller.WebhookService.Redeliver(ctx.Params("id"), ctx.Params("deliveryId"))
+	if delivery == nil {
+		return fiber.NewError(fiber.StatusNotFound, "webhook delivery not found")
+	}
+
+	return Render(ctx, fiber.StatusAccepted, model.SuccessResponse{
+		StatusCode: fiber.StatusAccepted,
+		Data:       delivery,
+	})
+}
@@ -2,17 +2,30 @@ CREATE DATABASE backendtest;
 USE backendtest;
 
 CREATE TABLE articles(
-    id          INT          NOT NULL AUTO_INCREMENT,
-    title       VARCHAR(100) NOT NULL,
-    slug        VARCHAR(100) NOT NULL,
-    category_id INT          NOT NULL,
-    content     TEXT         NOT NULL,
-    created_at  DATETIME     NOT NULL DEFAULT NOW(),
-    updated_at  DATETIME     NULL ON UPDATE NOW(),
-    deleted_at  DATETIME     NULL,
+    id                   INT          NOT NULL AUTO_INCREMENT,
+    title                VARCHAR(100) NOT NULL,
+    slug                 VARCHAR(100) NOT NULL,
+    category_id          INT          NOT NULL,
+    content              TEXT         NOT NULL,
+    content_format       VARCHAR(20)  NOT NULL DEFAULT 'markdown',
+    content_html         MEDIUMTEXT   NOT NULL,
+    content_text         MEDIUMTEXT   NOT NULL,
+    toc                  TEXT         NOT NULL,
+    excerpt              VARCHAR(255) NOT NULL DEFAULT '',
+    word_count           INT          NOT NULL DEFAULT 0,
+    reading_time_minutes INT          NOT NULL DEFAULT 0,
+    status               VARCHAR(20)  NOT NULL DEFAULT 'published',
+    featured_image_id    INT          NULL,
+    published_at         DATETIME     NULL,
+    created_at           DATETIME     NOT NULL DEFAULT NOW(),
+    updated_at           DATETIME     NULL ON UPDATE NOW(),
+    deleted_at           DATETIME     NULL,
     UNIQUE (slug),
+    INDEX (category_id, deleted_at, status, published_at),
+    INDEX (created_at),
+    INDEX (featured_image_id),
     PRIMARY KEY (id)
-) ENGINE = InnoDB
+) ENGINE = InnoDB;
 
 CREATE TABLE categories
 (
@@ -24,4 +37,121 @@ CREATE TABLE categories
     deleted_at    DATETIME    NULL,
     UNIQUE (category_name),
     PRIMARY KEY (id)
-) ENGINE = InnoDB
\ No newline at end of file
+) ENGINE = InnoDB;
+
+CREATE TABLE category_redirects
+(
+    old_slug    VARCHAR(30) NOT NULL,
+    category_id INT         NOT NULL,
+    created_at  DATETIME    NOT NULL DEFAULT NOW(),
+    INDEX (category_id),
+    PRIMARY KEY (old_slug)
+) ENGINE = InnoDB;
+
+CREATE TABLE comments
+(
+    id           INT          NOT NULL AUTO_INCREMENT,
+    article_id   INT          NOT NULL,
+    parent_id    INT          NULL,
+    author_name  VARCHAR(100) NOT NULL,
+    author_email VARCHAR(255) NOT NULL DEFAULT '',
+    body         TEXT         NOT NULL,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    created_at   DATETIME     NOT NULL DEFAULT NOW(),
+    updated_at   DATETIME     NULL ON UPDATE NOW(),
+    deleted_at   DATETIME     NULL,
+    INDEX (article_id, status, deleted_at),
+    INDEX (status, created_at),
+    INDEX (parent_id),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE media
+(
+    id           INT          NOT NULL AUTO_INCREMENT,
+    sha256       CHAR(64)     NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size         BIGINT       NOT NULL,
+    filename     VARCHAR(255) NOT NULL,
+    width        INT          NOT NULL DEFAULT 0,
+    height       INT          NOT NULL DEFAULT 0,
+    created_at   DATETIME     NOT NULL DEFAULT NOW(),
+    UNIQUE (sha256),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE article_attachments
+(
+    article_id INT      NOT NULL,
+    media_id   INT      NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT NOW(),
+    INDEX (media_id),
+    PRIMARY KEY (article_id, media_id)
+) ENGINE = InnoDB;
+
+CREATE TABLE webhooks
+(
+    id         INT           NOT NULL AUTO_INCREMENT,
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(255)  NOT NULL,
+    events     VARCHAR(1024) NOT NULL,
+    active     BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at DATETIME      NOT NULL DEFAULT NOW(),
+    updated_at DATETIME      NULL ON UPDATE NOW(),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE webhook_deliveries
+(
+    id              BIGINT      NOT NULL AUTO_INCREMENT,
+    webhook_id      INT         NOT NULL,
+    event_id        CHAR(36)    NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    payload         MEDIUMTEXT  NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME    NULL,
+    delivered_at    DATETIME    NULL,
+    created_at      DATETIME    NOT NULL DEFAULT NOW(),
+    UNIQUE (webhook_id, event_id),
+    INDEX (webhook_id, id),
+    INDEX (status, next_attempt_at),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE webhook_delivery_attempts
+(
+    id              BIGINT        NOT NULL AUTO_INCREMENT,
+    delivery_id     BIGINT        NOT NULL,
+    response_status INT           NULL,
+    error           VARCHAR(1024) NULL,
+    duration_ms     INT           NOT NULL DEFAULT 0,
+    created_at      DATETIME      NOT NULL DEFAULT NOW(),
+    INDEX (delivery_id),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE outbox
+(
+    id           BIGINT      NOT NULL AUTO_INCREMENT,
+    event_id     CHAR(36)    NOT NULL,
+    event_type   VARCHAR(50) NOT NULL,
+    article_id   INT         NOT NULL DEFAULT 0,
+    category_id  INT         NOT NULL DEFAULT 0,
+    payload      MEDIUMTEXT  NOT NULL,
+    created_at   DATETIME    NOT NULL DEFAULT NOW(),
+    published_at DATETIME    NULL,
+    UNIQUE (event_id),
+    INDEX (published_at, id),
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE import_mappings
+(
+    source      VARCHAR(255) NOT NULL,
+    kind        VARCHAR(20)  NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    target_id   INT          NOT NULL,
+    created_at  DATETIME     NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source, kind, external_id)
+) ENGINE = InnoDB;
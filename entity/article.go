@@ -4,13 +4,28 @@ import (
 	"time"
 )
 
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusPublished = "published"
+)
+
 type Article struct {
-	ID         int64
-	Title      string
-	Slug       string
-	CategoryID int64
-	Content    string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  time.Time
+	ID              int64
+	Title           string
+	Slug            string
+	CategoryID      int64
+	Content         string
+	ContentFormat   string
+	ContentHTML     string
+	ContentText     string
+	TOC             string
+	Excerpt         string
+	WordCount       int
+	ReadingTime     int
+	Status          string
+	FeaturedImageID int64
+	PublishedAt     time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       time.Time
 }
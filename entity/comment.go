@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
+type Comment struct {
+	ID          int64
+	ArticleID   int64
+	ParentID    int64
+	AuthorName  string
+	AuthorEmail string
+	Body        string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   time.Time
+}
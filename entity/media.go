@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+)
+
+type Media struct {
+	ID          int64
+	SHA256      string
+	ContentType string
+	Size        int64
+	Filename    string
+	Width       int
+	Height      int
+	CreatedAt   time.Time
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+)
+
+type Webhook struct {
+	ID        int64
+	URL       string
+	Secret    string
+	Events    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID            int64
+	WebhookID     int64
+	EventID       string
+	EventType     string
+	Payload       string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	DeliveredAt   time.Time
+	CreatedAt     time.Time
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64
+	DeliveryID     int64
+	ResponseStatus int
+	Error          string
+	DurationMS     int64
+	CreatedAt      time.Time
+}
@@ -0,0 +1,275 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// guidDate is the date of the tag URIs. RFC 4151 dates the authority, not
+// the resource, so it is fixed rather than taken from the article.
+const guidDate = "2021"
+
+type Site struct {
+	BaseURL string
+	SelfURL string
+
+	// GUIDHost is the configured authority of item identifiers. It never
+	// comes from the request, so that every host name serves the same IDs.
+	GUIDHost string
+}
+
+func (site Site) ArticleURL(article *model.ArticleResponse) string {
+	return site.BaseURL + "/article/" + strconv.FormatInt(article.ID, 10)
+}
+
+func (site Site) HomeURL(source *model.FeedSource) string {
+	if source.Category != nil {
+		return site.BaseURL + "/category/slug/" + source.Category.CategorySlug
+	}
+	return site.BaseURL + "/article"
+}
+
+// GUID derives a permanent identifier from the article ID alone, following
+// the tag URI scheme (RFC 4151) used by Atom. The slug is left out because it
+// changes with the title.
+func (site Site) GUID(article *model.ArticleResponse) string {
+	return fmt.Sprintf("tag:%s,%s:article/%d", site.GUIDHost, guidDate, article.ID)
+}
+
+// Hostname is the host of baseURL, or "" when it has none.
+func Hostname(baseURL string) string {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// Updated is the latest of the times an article was created, published or
+// edited.
+func Updated(article *model.ArticleResponse) time.Time {
+	updated := article.CreatedAt
+	if article.PublishedAt.After(updated) {
+		updated = article.PublishedAt
+	}
+	if article.UpdatedAt.After(updated) {
+		updated = article.UpdatedAt
+	}
+	return updated
+}
+
+func published(article *model.ArticleResponse) time.Time {
+	if article.PublishedAt.IsZero() {
+		return article.CreatedAt
+	}
+	return article.PublishedAt
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Content     string  `xml:"content:encoded"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Category    string  `xml:"category,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func RSS(site Site, source *model.FeedSource) ([]byte, error) {
+	channel := rssChannel{
+		Title:       source.Title,
+		Link:        site.HomeURL(source),
+		Description: source.Title,
+		AtomLink:    rssLink{Href: site.SelfURL, Rel: "self", Type: "application/rss+xml"},
+	}
+
+	if !source.Updated.IsZero() {
+		channel.LastBuildDate = source.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for i := range source.Articles {
+		article := &source.Articles[i]
+		channel.Items = append(channel.Items, rssItem{
+			Title:       article.Title,
+			Link:        site.ArticleURL(article),
+			Description: article.Excerpt,
+			Content:     article.ContentHTML,
+			GUID:        rssGUID{Value: site.GUID(article)},
+			PubDate:     published(article).UTC().Format(time.RFC1123Z),
+			Category:    article.CategoryName,
+		})
+	}
+
+	return marshalXML(rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	})
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string        `xml:"title"`
+	ID        string        `xml:"id"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []atomLink    `xml:"link"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   atomText      `xml:"summary"`
+	Content   atomText      `xml:"content"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func Atom(site Site, source *model.FeedSource) ([]byte, error) {
+	updated := source.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	feed := atomFeed{
+		Title:   source.Title,
+		ID:      site.SelfURL,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: site.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: site.HomeURL(source), Rel: "alternate"},
+		},
+	}
+
+	for i := range source.Articles {
+		article := &source.Articles[i]
+		entry := atomEntry{
+			Title:     article.Title,
+			ID:        site.GUID(article),
+			Updated:   Updated(article).UTC().Format(time.RFC3339),
+			Published: published(article).UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: site.ArticleURL(article), Rel: "alternate"}},
+			Summary:   atomText{Type: "text", Body: article.Excerpt},
+			Content:   atomText{Type: "html", Body: article.ContentHTML},
+		}
+
+		if article.CategorySlug != "" {
+			entry.Category = &atomCategory{Term: article.CategorySlug, Label: article.CategoryName}
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXML(feed)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	Summary       string   `json:"summary,omitempty"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+func JSON(site Site, source *model.FeedSource) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       source.Title,
+		HomePageURL: site.HomeURL(source),
+		FeedURL:     site.SelfURL,
+		Items:       []jsonFeedItem{},
+	}
+
+	for i := range source.Articles {
+		article := &source.Articles[i]
+		item := jsonFeedItem{
+			ID:            site.GUID(article),
+			URL:           site.ArticleURL(article),
+			Title:         article.Title,
+			ContentHTML:   article.ContentHTML,
+			Summary:       article.Excerpt,
+			DatePublished: published(article).UTC().Format(time.RFC3339),
+			DateModified:  Updated(article).UTC().Format(time.RFC3339),
+		}
+
+		if article.CategoryName != "" {
+			item.Tags = []string{article.CategoryName}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return json.Marshal(feed)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -5,4 +5,13 @@ go 1.16
 require (
 	github.com/gofiber/fiber/v2 v2.15.0
 	github.com/gosimple/slug v1.10.0
+	github.com/graphql-go/graphql v0.8.1
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	github.com/yuin/goldmark v1.4.13
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.26.0
+	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,212 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxIntrospectionLists bounds how deeply list fields nest below __schema or
+// __type. Lists are what make an introspection response grow, and the
+// standard introspection query needs three (types, fields, args).
+const maxIntrospectionLists = 3
+
+// Limits bound the size of a query before any of it runs. Zero disables a
+// limit.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Check measures the operation that would be executed. Every field costs one
+// and the fields below a paged field count once for each item it may return,
+// so complexity approximates the rows a query can read. Introspection fields
+// cost the same, but their depth is bounded by maxIntrospectionLists rather
+// than MaxDepth, which the usual introspection queries of tools exceed with
+// their chains of ofType. The document must have passed validation.
+func (limits Limits) Check(schema graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}) error {
+	m := measure{
+		schema:    schema,
+		variables: variables,
+		fragments: map[string]*ast.FragmentDefinition{},
+		visiting:  map[string]bool{},
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch d := definition.(type) {
+		case *ast.FragmentDefinition:
+			m.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				operation = d
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	depth, complexity, _ := m.selectionSet(root, operation.SelectionSet, 0)
+	if m.introspectionLists > maxIntrospectionLists {
+		return fmt.Errorf("introspection nests %d lists, more than the limit of %d", m.introspectionLists, maxIntrospectionLists)
+	}
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity)
+	}
+	return nil
+}
+
+type measure struct {
+	schema    graphql.Schema
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+
+	introspectionLists int
+}
+
+// selectionSet returns the depth reached below set, its cost and the most
+// list fields nested along any path through it.
+func (m *measure) selectionSet(parent *graphql.Object, set *ast.SelectionSet, depth int) (int, int, int) {
+	maxDepth, cost, maxLists := depth, 0, 0
+	if parent == nil || set == nil {
+		return maxDepth, cost, maxLists
+	}
+
+	for _, selection := range set.Selections {
+		var d, c, l int
+		switch s := selection.(type) {
+		case *ast.Field:
+			definition := fieldDefinition(parent, s.Name.Value)
+			if definition == nil {
+				continue
+			}
+			d, c, l = m.selectionSet(objectOf(definition.Type), s.SelectionSet, depth+1)
+			c = 1 + m.items(definition, s)*c
+			if isList(definition.Type) {
+				l++
+			}
+			if definition == graphql.SchemaMetaFieldDef || definition == graphql.TypeMetaFieldDef {
+				if l > m.introspectionLists {
+					m.introspectionLists = l
+				}
+				d = depth + 1
+			}
+		case *ast.InlineFragment:
+			d, c, l = m.selectionSet(m.typeCondition(parent, s.TypeCondition), s.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := m.fragments[s.Name.Value]
+			if !ok || m.visiting[s.Name.Value] {
+				continue
+			}
+			m.visiting[s.Name.Value] = true
+			d, c, l = m.selectionSet(m.typeCondition(parent, fragment.TypeCondition), fragment.SelectionSet, depth)
+			delete(m.visiting, s.Name.Value)
+		}
+
+		if d > maxDepth {
+			maxDepth = d
+		}
+		if l > maxLists {
+			maxLists = l
+		}
+		cost += c
+	}
+
+	return maxDepth, cost, maxLists
+}
+
+// items is the number of results a field may return: the value of its limit
+// argument, or that argument's default, or one for fields without one.
+func (m *measure) items(definition *graphql.FieldDefinition, field *ast.Field) int {
+	for _, argument := range definition.Args {
+		if argument.Name() != "limit" {
+			continue
+		}
+
+		limit, _ := argument.DefaultValue.(int)
+		for _, given := range field.Arguments {
+			if given.Name.Value == "limit" {
+				limit = m.intValue(given.Value, limit)
+			}
+		}
+		if limit < 1 {
+			return 1
+		}
+		return limit
+	}
+	return 1
+}
+
+func (m *measure) intValue(value ast.Value, fallback int) int {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		if n, err := strconv.Atoi(v.Value); err == nil {
+			return n
+		}
+	case *ast.Variable:
+		switch n := m.variables[v.Name.Value].(type) {
+		case int:
+			return n
+		case float64:
+			return int(n)
+		}
+	}
+	return fallback
+}
+
+// fieldDefinition looks up a field of parent, including the introspection
+// fields that every query may select without the schema declaring them.
+func fieldDefinition(parent *graphql.Object, name string) *graphql.FieldDefinition {
+	switch name {
+	case "__schema":
+		return graphql.SchemaMetaFieldDef
+	case "__type":
+		return graphql.TypeMetaFieldDef
+	case "__typename":
+		return graphql.TypeNameMetaFieldDef
+	}
+	return parent.Fields()[name]
+}
+
+func (m *measure) typeCondition(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := m.schema.Type(condition.Name.Value).(*graphql.Object)
+	return object
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+func objectOf(t graphql.Type) *graphql.Object {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t = wrapped.OfType
+		case *graphql.Object:
+			return wrapped
+		default:
+			return nil
+		}
+	}
+}
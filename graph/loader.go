@@ -0,0 +1,99 @@
+package graph
+
+import "sync"
+
+type loadResult struct {
+	value interface{}
+	err   error
+}
+
+// Loader collects the keys requested while one level of a query is being
+// resolved and fetches them with a single call once the first value is
+// needed. graphql-go resolves the thunks of a query breadth first, so every
+// key asked for at the same depth ends up in the same batch. Results are
+// kept for the rest of the request.
+type Loader struct {
+	fetch func(keys []int64) (map[int64]interface{}, error)
+
+	mu      sync.Mutex
+	pending []int64
+	queued  map[int64]bool
+	results map[int64]loadResult
+}
+
+func NewLoader(fetch func(keys []int64) (map[int64]interface{}, error)) *Loader {
+	return &Loader{
+		fetch:   fetch,
+		queued:  map[int64]bool{},
+		results: map[int64]loadResult{},
+	}
+}
+
+// Load queues key and returns a thunk for graphql-go to call later. Keys the
+// fetch did not return resolve to nil.
+func (l *Loader) Load(key int64) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, done := l.results[key]; !done && !l.queued[key] {
+		l.pending = append(l.pending, key)
+		l.queued[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, done := l.results[key]; !done {
+			l.dispatch()
+		}
+
+		result := l.results[key]
+		return result.value, result.err
+	}
+}
+
+func (l *Loader) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	l.queued = map[int64]bool{}
+
+	values, err := l.fetchSafely(keys)
+	for _, key := range keys {
+		l.results[key] = loadResult{value: values[key], err: err}
+	}
+}
+
+// fetchSafely turns a panicking service call into an error for the keys of
+// the batch, since the thunk that triggered it is only one of them.
+func (l *Loader) fetchSafely(keys []int64) (values map[int64]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	return l.fetch(keys)
+}
+
+// loaders holds the Loaders of one request, created on first use under a
+// name that identifies what they fetch.
+type loaders struct {
+	mu     sync.Mutex
+	byName map[string]*Loader
+}
+
+func (l *loaders) get(name string, fetch func(keys []int64) (map[int64]interface{}, error)) *Loader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loader, ok := l.byName[name]
+	if !ok {
+		loader = NewLoader(fetch)
+		l.byName[name] = loader
+	}
+	return loader
+}
@@ -0,0 +1,791 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// articleFieldColumns maps Article fields to the ArticleFields they are read
+// from, so a query only selects the columns it asks for.
+var articleFieldColumns = map[string][]string{
+	"id":                 {"id"},
+	"title":              {"title"},
+	"slug":               {"slug"},
+	"categoryId":         {"category_id"},
+	"category":           {"category_id"},
+	"content":            {"content"},
+	"contentFormat":      {"content_format"},
+	"contentHtml":        {"content_html"},
+	"toc":                {"toc"},
+	"excerpt":            {"excerpt"},
+	"wordCount":          {"word_count"},
+	"readingTimeMinutes": {"reading_time_minutes"},
+	"commentCount":       {"comment_count"},
+	"status":             {"status"},
+	"featuredImage":      {"featured_image"},
+	"publishedAt":        {"published_at"},
+	"createdAt":          {"created_at"},
+	"updatedAt":          {"updated_at"},
+	"deletedAt":          {"deleted_at"},
+}
+
+type articlePage struct {
+	items   []*model.ArticleResponse
+	hasMore bool
+	filter  *model.ArticleFilter
+}
+
+type categoryPage struct {
+	items      []*model.CategoryResponse
+	hasMore    bool
+	totalCount int
+}
+
+var articleStatusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "ArticleStatus",
+	Values: graphql.EnumValueConfigMap{
+		"PUBLISHED": {Value: entity.ArticleStatusPublished},
+		"DRAFT":     {Value: entity.ArticleStatusDraft},
+	},
+})
+
+var bulkModeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "BulkMode",
+	Values: graphql.EnumValueConfigMap{
+		"ATOMIC":      {Value: model.BulkModeAtomic},
+		"BEST_EFFORT": {Value: model.BulkModeBestEffort},
+	},
+})
+
+var tocEntryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TocEntry",
+	Fields: graphql.Fields{
+		"level": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: tocEntryField(func(e *model.TOCEntry) interface{} { return e.Level })},
+		"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: tocEntryField(func(e *model.TOCEntry) interface{} { return e.ID })},
+		"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: tocEntryField(func(e *model.TOCEntry) interface{} { return e.Title })},
+	},
+})
+
+var imageVariantType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ImageVariant",
+	Fields: graphql.Fields{
+		"size": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"url":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var imageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Image",
+	Fields: graphql.Fields{
+		"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: imageField(func(i *model.ImageResponse) interface{} { return i.ID })},
+		"width":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: imageField(func(i *model.ImageResponse) interface{} { return i.Width })},
+		"height": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: imageField(func(i *model.ImageResponse) interface{} { return i.Height })},
+		"url":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: imageField(func(i *model.ImageResponse) interface{} { return i.URL })},
+		"srcset": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: imageField(func(i *model.ImageResponse) interface{} { return i.Srcset })},
+		"variants": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(imageVariantType))), Resolve: imageField(func(i *model.ImageResponse) interface{} {
+			sizes := make([]string, 0, len(i.Variants))
+			for size := range i.Variants {
+				sizes = append(sizes, size)
+			}
+			sort.Strings(sizes)
+
+			variants := make([]map[string]interface{}, len(sizes))
+			for n, size := range sizes {
+				variants[n] = map[string]interface{}{"size": size, "url": i.Variants[size]}
+			}
+			return variants
+		})},
+	},
+})
+
+var categoryStatsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CategoryStats",
+	Fields: graphql.Fields{
+		"liveArticles":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: statsField(func(s *model.CategoryStats) interface{} { return s.LiveArticles })},
+		"draftArticles":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: statsField(func(s *model.CategoryStats) interface{} { return s.DraftArticles })},
+		"deletedArticles": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: statsField(func(s *model.CategoryStats) interface{} { return s.DeletedArticles })},
+		"lastPublishedAt": &graphql.Field{Type: graphql.DateTime, Resolve: statsField(func(s *model.CategoryStats) interface{} {
+			if s.LastPublishedAt == nil {
+				return nil
+			}
+			return *s.LastPublishedAt
+		})},
+	},
+})
+
+var moveResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "MoveArticlesResult",
+	Fields: graphql.Fields{
+		"categoryId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*model.ArticleMoveResponse).CategoryID, nil
+		}},
+		"moved": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*model.ArticleMoveResponse).Moved, nil
+		}},
+	},
+})
+
+var bulkItemType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BulkItemResult",
+	Fields: graphql.Fields{
+		"index":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: bulkItemField(func(r *model.BulkItemResult) interface{} { return r.Index })},
+		"id":     &graphql.Field{Type: graphql.ID, Resolve: bulkItemField(func(r *model.BulkItemResult) interface{} { return nullID(r.ID) })},
+		"status": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: bulkItemField(func(r *model.BulkItemResult) interface{} { return r.Status })},
+		"error":  &graphql.Field{Type: graphql.String, Resolve: bulkItemField(func(r *model.BulkItemResult) interface{} { return nullString(r.Error) })},
+	},
+})
+
+var bulkResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BulkResult",
+	Fields: graphql.Fields{
+		"mode":      &graphql.Field{Type: graphql.NewNonNull(bulkModeEnum), Resolve: bulkField(func(r *model.BulkResponse) interface{} { return r.Mode })},
+		"succeeded": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: bulkField(func(r *model.BulkResponse) interface{} { return r.Succeeded })},
+		"failed":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: bulkField(func(r *model.BulkResponse) interface{} { return r.Failed })},
+		"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bulkItemType))), Resolve: bulkField(func(r *model.BulkResponse) interface{} {
+			items := make([]*model.BulkItemResult, len(r.Items))
+			for i := range r.Items {
+				items[i] = &r.Items[i]
+			}
+			return items
+		})},
+	},
+})
+
+var articleInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ArticleInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":           &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"categoryId":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+		"content":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"contentFormat":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"status":          &graphql.InputObjectFieldConfig{Type: articleStatusEnum},
+		"featuredImageId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+	},
+})
+
+func pageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize, Description: "At most " + strconv.Itoa(maxPageSize) + "."},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+	}
+}
+
+func articleFilterArgs() graphql.FieldConfigArgument {
+	args := pageArgs()
+	args["status"] = &graphql.ArgumentConfig{Type: articleStatusEnum}
+	args["title"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "Regular expression matched against the lowercased title."}
+	return args
+}
+
+func (s *Server) buildSchema() (graphql.Schema, error) {
+	var articleType, categoryType *graphql.Object
+
+	categoryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return c.ID })},
+				"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return c.CategoryName })},
+				"slug":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return c.CategorySlug })},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return c.CreatedAt })},
+				"updatedAt": &graphql.Field{Type: graphql.DateTime, Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return nullTime(c.UpdatedAt) })},
+				"deletedAt": &graphql.Field{Type: graphql.DateTime, Resolve: categoryField(func(c *model.CategoryResponse) interface{} { return nullTime(c.DeletedAt) })},
+				"stats": &graphql.Field{
+					Type:        categoryStatsType,
+					Description: "Article counters, read for all categories at the same depth at once.",
+					Resolve:     s.resolveCategoryStats,
+				},
+				"articles": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(articleType))),
+					Description: "The live articles of the category. Sibling categories are read in one query.",
+					Args:        articleFilterArgs(),
+					Resolve:     s.resolveCategoryArticles,
+				},
+			}
+		}),
+	})
+
+	articleType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Article",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.ID })},
+				"title":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.Title })},
+				"slug":       &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.Slug })},
+				"categoryId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.CategoryID })},
+				"category": &graphql.Field{
+					Type:        categoryType,
+					Description: "Read for all articles at the same depth in one query.",
+					Resolve:     s.resolveArticleCategory,
+				},
+				"content":            &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.Content })},
+				"contentFormat":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.ContentFormat })},
+				"contentHtml":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.ContentHTML })},
+				"toc":                &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tocEntryType))), Resolve: articleField(tocEntries)},
+				"excerpt":            &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.Excerpt })},
+				"wordCount":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.WordCount })},
+				"readingTimeMinutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.ReadingTime })},
+				"commentCount":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.CommentCount })},
+				"status":             &graphql.Field{Type: graphql.NewNonNull(articleStatusEnum), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.Status })},
+				"featuredImage":      &graphql.Field{Type: imageType, Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.FeaturedImage })},
+				"publishedAt":        &graphql.Field{Type: graphql.DateTime, Resolve: articleField(func(a *model.ArticleResponse) interface{} { return nullTime(a.PublishedAt) })},
+				"createdAt":          &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: articleField(func(a *model.ArticleResponse) interface{} { return a.CreatedAt })},
+				"updatedAt":          &graphql.Field{Type: graphql.DateTime, Resolve: articleField(func(a *model.ArticleResponse) interface{} { return nullTime(a.UpdatedAt) })},
+				"deletedAt":          &graphql.Field{Type: graphql.DateTime, Resolve: articleField(func(a *model.ArticleResponse) interface{} { return nullTime(a.DeletedAt) })},
+			}
+		}),
+	})
+
+	articlePageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ArticlePage",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(articleType))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*articlePage).items, nil
+			}},
+			"hasMore": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*articlePage).hasMore, nil
+			}},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Counted only when asked for.", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.articleService.Count(p.Source.(*articlePage).filter), nil
+			}},
+		},
+	})
+
+	categoryPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CategoryPage",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*categoryPage).items, nil
+			}},
+			"hasMore": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*categoryPage).hasMore, nil
+			}},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*categoryPage).totalCount, nil
+			}},
+		},
+	})
+
+	articlesArgs := articleFilterArgs()
+	articlesArgs["categoryId"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))}
+
+	categoriesArgs := pageArgs()
+	categoriesArgs["name"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "Case-insensitive substring of the name."}
+
+	lookupArgs := graphql.FieldConfigArgument{
+		"id":   &graphql.ArgumentConfig{Type: graphql.ID},
+		"slug": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	bulkIDArgs := graphql.FieldConfigArgument{
+		"ids":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+		"mode": &graphql.ArgumentConfig{Type: bulkModeEnum, DefaultValue: model.BulkModeAtomic},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"article":    &graphql.Field{Type: articleType, Description: "Look up an article by id or slug.", Args: lookupArgs, Resolve: s.resolveArticle},
+			"articles":   &graphql.Field{Type: graphql.NewNonNull(articlePageType), Description: "Live articles in ID order.", Args: articlesArgs, Resolve: s.resolveArticles},
+			"category":   &graphql.Field{Type: categoryType, Description: "Look up a category by id or slug. Slugs of merged categories find the merge target.", Args: lookupArgs, Resolve: s.resolveCategory},
+			"categories": &graphql.Field{Type: graphql.NewNonNull(categoryPageType), Description: "Live categories.", Args: categoriesArgs, Resolve: s.resolveCategories},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createArticle": &graphql.Field{
+				Type: graphql.NewNonNull(articleType),
+				Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(articleInputType)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					request, err := articleRequest(p.Args["input"])
+					if err != nil {
+						return nil, err
+					}
+					return s.articleService.Create((*model.ArticleCreateRequest)(request)), nil
+				},
+			},
+			"createArticles": &graphql.Field{
+				Type: graphql.NewNonNull(bulkResultType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(articleInputType)))},
+					"mode":  &graphql.ArgumentConfig{Type: bulkModeEnum, DefaultValue: model.BulkModeAtomic},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					inputs, _ := p.Args["input"].([]interface{})
+					items := make([]json.RawMessage, len(inputs))
+					for i, input := range inputs {
+						request, err := articleRequest(input)
+						if err != nil {
+							return nil, err
+						}
+						items[i], err = json.Marshal((*model.ArticleCreateRequest)(request))
+						if err != nil {
+							return nil, err
+						}
+					}
+					return s.articleService.CreateBulk(items, stringArg(p.Args, "mode")), nil
+				},
+			},
+			"updateArticle": &graphql.Field{
+				Type: graphql.NewNonNull(articleType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(articleInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					request, err := articleRequest(p.Args["input"])
+					if err != nil {
+						return nil, err
+					}
+					return s.articleService.Update(stringArg(p.Args, "id"), request), nil
+				},
+			},
+			"softDeleteArticle": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s.articleService.SoftDelete(stringArg(p.Args, "id"))
+					return true, nil
+				},
+			},
+			"softDeleteArticles": &graphql.Field{
+				Type: graphql.NewNonNull(bulkResultType),
+				Args: bulkIDArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids, err := idsArg(p.Args, "ids")
+					if err != nil {
+						return nil, err
+					}
+					return s.articleService.SoftDeleteBulk(&model.BulkDeleteRequest{IDs: ids}, stringArg(p.Args, "mode")), nil
+				},
+			},
+			"deleteArticle": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Deletes a trashed article for good.",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s.articleService.Delete(stringArg(p.Args, "id"))
+					return true, nil
+				},
+			},
+			"deleteArticles": &graphql.Field{
+				Type:        graphql.NewNonNull(bulkResultType),
+				Description: "Deletes trashed articles for good.",
+				Args:        bulkIDArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids, err := idsArg(p.Args, "ids")
+					if err != nil {
+						return nil, err
+					}
+					return s.articleService.DeleteBulk(&model.BulkDeleteRequest{IDs: ids}, stringArg(p.Args, "mode")), nil
+				},
+			},
+			"moveArticles": &graphql.Field{
+				Type: graphql.NewNonNull(moveResultType),
+				Args: graphql.FieldConfigArgument{
+					"articleIds": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+					"categoryId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					articleIDs, err := idsArg(p.Args, "articleIds")
+					if err != nil {
+						return nil, err
+					}
+					categoryID, err := parseID(p.Args["categoryId"])
+					if err != nil {
+						return nil, err
+					}
+					return s.articleService.MoveToCategory(&model.ArticleMoveRequest{ArticleIDs: articleIDs, CategoryID: categoryID}), nil
+				},
+			},
+			"createCategory": &graphql.Field{
+				Type: graphql.NewNonNull(categoryType),
+				Args: graphql.FieldConfigArgument{"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.categoryService.Create(&model.CategoryCreateRequest{CategoryName: stringArg(p.Args, "name")}), nil
+				},
+			},
+			"updateCategory": &graphql.Field{
+				Type: graphql.NewNonNull(categoryType),
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.categoryService.Update(stringArg(p.Args, "id"), &model.CategoryUpdateRequest{CategoryName: stringArg(p.Args, "name")}), nil
+				},
+			},
+			"softDeleteCategory": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s.categoryService.SoftDelete(stringArg(p.Args, "id"))
+					return true, nil
+				},
+			},
+			"deleteCategory": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Deletes a trashed category for good.",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s.categoryService.Delete(stringArg(p.Args, "id"))
+					return true, nil
+				},
+			},
+			"mergeCategory": &graphql.Field{
+				Type:        graphql.NewNonNull(categoryType),
+				Description: "Moves the articles of a category to the target and trashes it. Returns the target.",
+				Args: graphql.FieldConfigArgument{
+					"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"targetId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					targetID, err := parseID(p.Args["targetId"])
+					if err != nil {
+						return nil, err
+					}
+					return s.categoryService.Merge(stringArg(p.Args, "id"), &model.CategoryMergeRequest{TargetID: targetID}), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func (s *Server) resolveArticle(p graphql.ResolveParams) (interface{}, error) {
+	selection := articleSelection(p.Info)
+	if id, ok := p.Args["id"].(string); ok {
+		return s.articleService.FindOne(id, selection), nil
+	}
+	if articleSlug, ok := p.Args["slug"].(string); ok {
+		return s.articleService.FindOneBySlug(articleSlug, selection), nil
+	}
+	return nil, errors.New("article needs an id or a slug")
+}
+
+func (s *Server) resolveArticles(p graphql.ResolveParams) (interface{}, error) {
+	filter, err := articleFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter.CategoryIDs, err = idsArg(p.Args, "categoryId")
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows.
+	peek := *filter
+	peek.Limit++
+	articles := pointersToArticles(s.articleService.ListPage(&peek, articleSelection(p.Info, "items")))
+
+	page := &articlePage{items: articles, filter: filter}
+	if len(articles) > filter.Limit {
+		page.items, page.hasMore = articles[:filter.Limit], true
+	}
+	return page, nil
+}
+
+func (s *Server) resolveCategory(p graphql.ResolveParams) (interface{}, error) {
+	if id, ok := p.Args["id"].(string); ok {
+		return s.categoryService.FindOne(id), nil
+	}
+	if categorySlug, ok := p.Args["slug"].(string); ok {
+		return s.categoryService.FindOneBySlug(categorySlug), nil
+	}
+	return nil, errors.New("category needs an id or a slug")
+}
+
+func (s *Server) resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+	limit, offset, err := pageBounds(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(stringArg(p.Args, "name"))
+	var matched []*model.CategoryResponse
+	if categories := s.categoryService.List(); categories != nil {
+		for i := range *categories {
+			category := &(*categories)[i]
+			if strings.Contains(strings.ToLower(category.CategoryName), name) {
+				matched = append(matched, category)
+			}
+		}
+	}
+
+	page := &categoryPage{items: []*model.CategoryResponse{}, totalCount: len(matched)}
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.items, page.hasMore = matched[offset:end], end < len(matched)
+	}
+	return page, nil
+}
+
+func (s *Server) resolveArticleCategory(p graphql.ResolveParams) (interface{}, error) {
+	article := p.Source.(*model.ArticleResponse)
+	loader := loadersFrom(p.Context).get("categories", func(keys []int64) (map[int64]interface{}, error) {
+		values := map[int64]interface{}{}
+		if categories := s.categoryService.FindAllByIDs(keys, false); categories != nil {
+			for i := range *categories {
+				values[(*categories)[i].ID] = &(*categories)[i]
+			}
+		}
+		return values, nil
+	})
+
+	return loader.Load(article.CategoryID), nil
+}
+
+func (s *Server) resolveCategoryStats(p graphql.ResolveParams) (interface{}, error) {
+	category := p.Source.(*model.CategoryResponse)
+	if category.Stats != nil {
+		return category.Stats, nil
+	}
+
+	loader := loadersFrom(p.Context).get("categoryStats", func(keys []int64) (map[int64]interface{}, error) {
+		values := map[int64]interface{}{}
+		if categories := s.categoryService.FindAllByIDs(keys, true); categories != nil {
+			for _, withStats := range *categories {
+				values[withStats.ID] = withStats.Stats
+			}
+		}
+		return values, nil
+	})
+
+	return loader.Load(category.ID), nil
+}
+
+func (s *Server) resolveCategoryArticles(p graphql.ResolveParams) (interface{}, error) {
+	category := p.Source.(*model.CategoryResponse)
+	filter, err := articleFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	selection := articleSelection(p.Info)
+	name := fmt.Sprintf("articles:%d:%d:%s:%q:%s", filter.Limit, filter.Offset, filter.Status, filter.Title, strings.Join(selection.Fields, ","))
+	loader := loadersFrom(p.Context).get(name, func(keys []int64) (map[int64]interface{}, error) {
+		values := map[int64]interface{}{}
+		for categoryID, articles := range s.articleService.ListPageByCategories(keys, filter, selection) {
+			articles := articles
+			values[categoryID] = pointersToArticles(&articles)
+		}
+		return values, nil
+	})
+
+	load := loader.Load(category.ID)
+	return func() (interface{}, error) {
+		articles, err := load()
+		if articles == nil && err == nil {
+			return []*model.ArticleResponse{}, nil
+		}
+		return articles, err
+	}, nil
+}
+
+// articleSelection selects the columns behind the Article fields the query
+// asks for, below the fields named by path if any.
+func articleSelection(info graphql.ResolveInfo, path ...string) *model.Selection {
+	requested := map[string]bool{"id": true}
+	for name := range selectedFields(info, path...) {
+		for _, column := range articleFieldColumns[name] {
+			requested[column] = true
+		}
+	}
+
+	selection := &model.Selection{}
+	for _, field := range model.ArticleFields {
+		if requested[field] {
+			selection.Fields = append(selection.Fields, field)
+		}
+	}
+	return selection
+}
+
+func articleFilter(args map[string]interface{}) (*model.ArticleFilter, error) {
+	limit, offset, err := pageBounds(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ArticleFilter{
+		Status: stringArg(args, "status"),
+		Title:  stringArg(args, "title"),
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func pageBounds(args map[string]interface{}) (int, int, error) {
+	limit, _ := args["limit"].(int)
+	offset, _ := args["offset"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset cannot be negative")
+	}
+	return limit, offset, nil
+}
+
+// articleRequest converts an ArticleInput. Create and update requests share
+// their fields.
+func articleRequest(input interface{}) (*model.ArticleUpdateRequest, error) {
+	fields, _ := input.(map[string]interface{})
+
+	categoryID, err := parseID(fields["categoryId"])
+	if err != nil {
+		return nil, err
+	}
+
+	var featuredImageID int64
+	if fields["featuredImageId"] != nil {
+		featuredImageID, err = parseID(fields["featuredImageId"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.ArticleUpdateRequest{
+		Title:           stringArg(fields, "title"),
+		CategoryID:      categoryID,
+		Content:         stringArg(fields, "content"),
+		ContentFormat:   stringArg(fields, "contentFormat"),
+		Status:          stringArg(fields, "status"),
+		FeaturedImageID: featuredImageID,
+	}, nil
+}
+
+func parseID(value interface{}) (int64, error) {
+	id, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", fmt.Sprint(value))
+	}
+	return id, nil
+}
+
+func idsArg(args map[string]interface{}, name string) ([]int64, error) {
+	values, _ := args[name].([]interface{})
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := parseID(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func pointersToArticles(articles *[]model.ArticleResponse) []*model.ArticleResponse {
+	pointers := []*model.ArticleResponse{}
+	if articles == nil {
+		return pointers
+	}
+	for i := range *articles {
+		pointers = append(pointers, &(*articles)[i])
+	}
+	return pointers
+}
+
+func tocEntries(a *model.ArticleResponse) interface{} {
+	entries := make([]*model.TOCEntry, len(a.TOC))
+	for i := range a.TOC {
+		entries[i] = &a.TOC[i]
+	}
+	return entries
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func nullID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func articleField(get func(a *model.ArticleResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.ArticleResponse)), nil
+	}
+}
+
+func categoryField(get func(c *model.CategoryResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.CategoryResponse)), nil
+	}
+}
+
+func statsField(get func(s *model.CategoryStats) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.CategoryStats)), nil
+	}
+}
+
+func imageField(get func(i *model.ImageResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.ImageResponse)), nil
+	}
+}
+
+func tocEntryField(get func(e *model.TOCEntry) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.TOCEntry)), nil
+	}
+}
+
+func bulkField(get func(r *model.BulkResponse) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.BulkResponse)), nil
+	}
+}
+
+func bulkItemField(get func(r *model.BulkItemResult) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.BulkItemResult)), nil
+	}
+}
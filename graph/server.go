@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/muhammadrijalkamal/backendtest/service"
+)
+
+type loadersKey struct{}
+
+// Request is a GraphQL request as sent in a POST body or the query string of
+// a GET.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Server executes GraphQL requests against the article and category services.
+type Server struct {
+	articleService  service.ArticleService
+	categoryService service.CategoryService
+	limits          Limits
+	schema          graphql.Schema
+}
+
+func NewServer(articleService *service.ArticleService, categoryService *service.CategoryService, limits Limits) (*Server, error) {
+	s := &Server{
+		articleService:  *articleService,
+		categoryService: *categoryService,
+		limits:          limits,
+	}
+
+	schema, err := s.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	s.schema = schema
+	return s, nil
+}
+
+// Execute parses, validates and measures the request before running any
+// resolver. Mutations are refused unless allowMutations, so that GET
+// requests cannot change anything. Each request gets its own loaders.
+func (s *Server) Execute(ctx context.Context, request Request, allowMutations bool) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if !allowMutations && isMutation(document, request.OperationName) {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("mutations must be sent with POST"))}
+	}
+
+	if err := s.limits.Check(s.schema, document, request.OperationName, request.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, &loaders{byName: map[string]*Loader{}}),
+	})
+}
+
+func isMutation(document *ast.Document, operationName string) bool {
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			return operation.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// selectedFields collects the names of the fields selected below the field
+// being resolved, through fragments, after descending into the child fields
+// named by path.
+func selectedFields(info graphql.ResolveInfo, path ...string) map[string]bool {
+	sets := make([]*ast.SelectionSet, 0, len(info.FieldASTs))
+	for _, field := range info.FieldASTs {
+		sets = append(sets, field.SelectionSet)
+	}
+
+	for _, name := range path {
+		var children []*ast.SelectionSet
+		eachField(sets, info.Fragments, func(field *ast.Field) {
+			if field.Name.Value == name {
+				children = append(children, field.SelectionSet)
+			}
+		})
+		sets = children
+	}
+
+	names := map[string]bool{}
+	eachField(sets, info.Fragments, func(field *ast.Field) {
+		names[field.Name.Value] = true
+	})
+	return names
+}
+
+func eachField(sets []*ast.SelectionSet, fragments map[string]ast.Definition, fn func(field *ast.Field)) {
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, selection := range set.Selections {
+			switch s := selection.(type) {
+			case *ast.Field:
+				fn(s)
+			case *ast.InlineFragment:
+				eachField([]*ast.SelectionSet{s.SelectionSet}, fragments, fn)
+			case *ast.FragmentSpread:
+				if fragment, ok := fragments[s.Name.Value].(*ast.FragmentDefinition); ok {
+					eachField([]*ast.SelectionSet{fragment.SelectionSet}, fragments, fn)
+				}
+			}
+		}
+	}
+}
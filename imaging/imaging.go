@@ -0,0 +1,167 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxPixels bounds the decoded size of a source image so a small, highly
+// compressed upload can't exhaust memory when a variant is generated.
+const maxPixels = 50000000
+
+const jpegQuality = 85
+
+var sizeNamePattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Size is a named variant width. Images are never scaled up, so the variant
+// of a narrower source keeps the source width.
+type Size struct {
+	Name  string
+	Width int
+}
+
+var DefaultSizes = []Size{
+	{Name: "thumb", Width: 160},
+	{Name: "medium", Width: 640},
+	{Name: "large", Width: 1280},
+}
+
+// ParseSizes reads a list like "thumb=160,medium=640,large=1280".
+func ParseSizes(spec string) ([]Size, error) {
+	var sizes []Size
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(parts) != 2 || !sizeNamePattern.MatchString(parts[0]) {
+			return nil, fmt.Errorf("invalid image size %q", item)
+		}
+
+		width, err := strconv.Atoi(parts[1])
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid image size %q", item)
+		}
+
+		if _, ok := Find(sizes, parts[0]); ok {
+			return nil, fmt.Errorf("duplicate image size %q", parts[0])
+		}
+
+		sizes = append(sizes, Size{Name: parts[0], Width: width})
+	}
+
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Width < sizes[j].Width })
+	return sizes, nil
+}
+
+func Find(sizes []Size, name string) (Size, bool) {
+	for _, size := range sizes {
+		if size.Name == name {
+			return size, true
+		}
+	}
+	return Size{}, false
+}
+
+// IsImage reports whether variants can be generated for a content type.
+func IsImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Dimensions reads the width and height from the image header and rewinds r.
+func Dimensions(r io.ReadSeeker) (int, int, error) {
+	config, _, err := image.DecodeConfig(r)
+	if _, seekErr := r.Seek(0, io.SeekStart); seekErr != nil {
+		return 0, 0, seekErr
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+// Resize scales the image down to width and encodes it as PNG when the
+// source may carry transparency or is a graphic, and as JPEG otherwise.
+func Resize(r io.ReadSeeker, width int) ([]byte, string, error) {
+	srcWidth, srcHeight, err := Dimensions(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if srcWidth*srcHeight > maxPixels {
+		return nil, "", errors.New("image is too large to resize")
+	}
+
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := src.Bounds()
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+
+	height := (bounds.Dy()*width + bounds.Dx()/2) / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" || !dst.Opaque() {
+		err = png.Encode(&buf, dst)
+		return buf.Bytes(), "image/png", err
+	}
+
+	err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+	return buf.Bytes(), "image/jpeg", err
+}
+
+// Describe fills in the URLs of an image and its variants, including a
+// srcset with the width each variant actually has.
+func Describe(img *model.ImageResponse, sizes []Size) {
+	img.URL = model.MediaURL(img.ID)
+	img.Variants = make(map[string]string, len(sizes))
+
+	seen := map[int]bool{}
+	var srcset []string
+	for _, size := range sizes {
+		url := model.MediaVariantURL(img.ID, size.Name)
+		img.Variants[size.Name] = url
+
+		width := size.Width
+		if img.Width > 0 && width > img.Width {
+			width = img.Width
+		}
+
+		if !seen[width] {
+			seen[width] = true
+			srcset = append(srcset, url+" "+strconv.Itoa(width)+"w")
+		}
+	}
+
+	if img.Width > 0 && !seen[img.Width] {
+		srcset = append(srcset, img.URL+" "+strconv.Itoa(img.Width)+"w")
+	}
+
+	img.Srcset = strings.Join(srcset, ", ")
+}
@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownExtensions are the file extensions picked up by a Markdown import.
+var MarkdownExtensions = []string{".md", ".markdown"}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// MarkdownPost is a legacy post: YAML front matter followed by the body.
+type MarkdownPost struct {
+	Path     string
+	Title    string
+	Category string
+	Slug     string
+	Date     time.Time
+	Tags     []string
+	Draft    bool
+	Content  string
+}
+
+type frontMatter struct {
+	Title    string      `yaml:"title"`
+	Category string      `yaml:"category"`
+	Date     string      `yaml:"date"`
+	Tags     interface{} `yaml:"tags"`
+	Slug     string      `yaml:"slug"`
+	Draft    bool        `yaml:"draft"`
+}
+
+// ParseMarkdown splits the front matter from the body. Tags may be a YAML
+// list or a comma-separated string.
+func ParseMarkdown(file SourceFile) (*MarkdownPost, error) {
+	data := bytes.TrimPrefix(file.Data, []byte("\xef\xbb\xbf"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	if !bytes.HasPrefix(data, []byte("---\n")) {
+		return nil, errors.New("missing front matter")
+	}
+
+	end := bytes.Index(data[4:], []byte("\n---"))
+	if end < 0 {
+		return nil, errors.New("unterminated front matter")
+	}
+
+	header := data[4 : 4+end]
+	body := data[4+end+4:]
+	if i := bytes.IndexByte(body, '\n'); i >= 0 && len(bytes.TrimSpace(body[:i])) == 0 {
+		body = body[i+1:]
+	}
+
+	var matter frontMatter
+	if err := yaml.Unmarshal(header, &matter); err != nil {
+		return nil, fmt.Errorf("front matter: %v", err)
+	}
+
+	post := &MarkdownPost{
+		Path:     file.Path,
+		Title:    strings.TrimSpace(matter.Title),
+		Category: strings.TrimSpace(matter.Category),
+		Slug:     strings.TrimSpace(matter.Slug),
+		Draft:    matter.Draft,
+		Content:  strings.TrimSpace(string(body)),
+	}
+
+	if post.Title == "" {
+		return nil, errors.New("front matter has no title")
+	}
+
+	if post.Category == "" {
+		return nil, errors.New("front matter has no category")
+	}
+
+	if post.Slug == "" {
+		post.Slug = strings.TrimSuffix(path.Base(file.Path), path.Ext(file.Path))
+	}
+
+	if matter.Date != "" {
+		date, err := parseDate(matter.Date)
+		if err != nil {
+			return nil, err
+		}
+		post.Date = date
+	}
+
+	switch tags := matter.Tags.(type) {
+	case string:
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				post.Tags = append(post.Tags, tag)
+			}
+		}
+	case []interface{}:
+		for _, tag := range tags {
+			post.Tags = append(post.Tags, fmt.Sprint(tag))
+		}
+	}
+
+	return post, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxSourceFiles     = 10000
+	maxSourceFileSize  = 10 * 1024 * 1024
+	maxSourceTotalSize = 256 * 1024 * 1024
+)
+
+var errSourceTooLarge = fmt.Errorf("files to import exceed %d bytes in total", maxSourceTotalSize)
+
+// SourceFile is one file read from an import directory or archive. Path is
+// slash-separated and relative to the root.
+type SourceFile struct {
+	Path string
+	Data []byte
+}
+
+// ReadDir collects the files below root whose extension is listed.
+func ReadDir(root string, extensions ...string) ([]SourceFile, error) {
+	var files []SourceFile
+	var total int64
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if rel != "." && hidden(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !wanted(rel, extensions) {
+			return nil
+		}
+
+		if info.Size() > maxSourceFileSize {
+			return fmt.Errorf("%s exceeds %d bytes", rel, maxSourceFileSize)
+		}
+
+		total += info.Size()
+		if total > maxSourceTotalSize {
+			return errSourceTooLarge
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, SourceFile{Path: rel, Data: data})
+		if len(files) > maxSourceFiles {
+			return fmt.Errorf("more than %d files to import", maxSourceFiles)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// ReadArchive collects the matching files of a zip, tar or gzipped tar
+// archive. The format is detected from the content, not the file name.
+func ReadArchive(r io.ReaderAt, size int64, extensions ...string) ([]SourceFile, error) {
+	head := make([]byte, 512)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return readZip(r, size, extensions)
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return readTar(gz, extensions)
+	case len(head) > 262 && string(head[257:262]) == "ustar":
+		return readTar(io.NewSectionReader(r, 0, size), extensions)
+	}
+
+	return nil, errors.New("unsupported archive: expected zip, tar or tar.gz")
+}
+
+func readZip(r io.ReaderAt, size int64, extensions []string) ([]SourceFile, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []SourceFile
+	var total int64
+	for _, entry := range archive.File {
+		name := cleanArchivePath(entry.Name)
+		if entry.FileInfo().IsDir() || name == "" || !wanted(name, extensions) {
+			continue
+		}
+
+		if entry.UncompressedSize64 > maxSourceFileSize {
+			return nil, fmt.Errorf("%s exceeds %d bytes", name, maxSourceFileSize)
+		}
+
+		if uint64(total)+entry.UncompressedSize64 > maxSourceTotalSize {
+			return nil, errSourceTooLarge
+		}
+
+		content, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := readLimited(content, &total)
+		content.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		files = append(files, SourceFile{Path: name, Data: data})
+		if len(files) > maxSourceFiles {
+			return nil, fmt.Errorf("more than %d files to import", maxSourceFiles)
+		}
+	}
+
+	sortFiles(files)
+	return files, nil
+}
+
+func readTar(r io.Reader, extensions []string) ([]SourceFile, error) {
+	archive := tar.NewReader(r)
+
+	var files []SourceFile
+	var total int64
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := cleanArchivePath(header.Name)
+		if header.Typeflag != tar.TypeReg || name == "" || !wanted(name, extensions) {
+			continue
+		}
+
+		data, err := readLimited(archive, &total)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		files = append(files, SourceFile{Path: name, Data: data})
+		if len(files) > maxSourceFiles {
+			return nil, fmt.Errorf("more than %d files to import", maxSourceFiles)
+		}
+	}
+
+	sortFiles(files)
+	return files, nil
+}
+
+// readLimited reads one file and adds its size to the total read from the
+// archive so far. The sizes an archive declares are not trusted: both limits
+// are enforced on the bytes actually decompressed.
+func readLimited(r io.Reader, total *int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSourceFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxSourceFileSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxSourceFileSize)
+	}
+
+	*total += int64(len(data))
+	if *total > maxSourceTotalSize {
+		return nil, errSourceTooLarge
+	}
+	return data, nil
+}
+
+// cleanArchivePath drops entries that would escape the archive root.
+func cleanArchivePath(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, `\`, "/"))[1:]
+	if name == "" || hidden(name) {
+		return ""
+	}
+	return name
+}
+
+func hidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+func wanted(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	for _, extension := range extensions {
+		if ext == extension {
+			return true
+		}
+	}
+	return false
+}
+
+func sortFiles(files []SourceFile) {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+}
@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// WXR holds the parts of a WordPress export that map onto articles and
+// categories. Pages, attachments, menu items and other post types are
+// dropped while reading.
+type WXR struct {
+	SiteURL    string
+	Categories []WXRCategory
+	Posts      []WXRPost
+}
+
+type WXRCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Name     string `xml:"cat_name"`
+	Parent   string `xml:"category_parent"`
+}
+
+type WXRPost struct {
+	ID         int64
+	Title      string
+	Link       string
+	GUID       string
+	Name       string
+	Status     string
+	Date       time.Time
+	Content    string
+	Categories []WXRTerm
+	Tags       []string
+}
+
+type WXRTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrItem struct {
+	Title    string    `xml:"title"`
+	Link     string    `xml:"link"`
+	GUID     string    `xml:"guid"`
+	Content  string    `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID   int64     `xml:"post_id"`
+	PostName string    `xml:"post_name"`
+	PostType string    `xml:"post_type"`
+	Status   string    `xml:"status"`
+	Date     string    `xml:"post_date"`
+	DateGMT  string    `xml:"post_date_gmt"`
+	Terms    []WXRTerm `xml:"category"`
+}
+
+// ReadWXR decodes an export item by item so that attachments and other
+// ignored items are never held in memory.
+func ReadWXR(r io.Reader) (*WXR, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	export := &WXR{}
+	var channelLink string
+	inChannel := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			if end, ok := token.(xml.EndElement); ok && end.Name.Local == "channel" {
+				inChannel = false
+			}
+			continue
+		}
+
+		switch {
+		case start.Name.Local == "channel":
+			inChannel = true
+		case !inChannel:
+			continue
+		case start.Name.Local == "base_site_url" || (start.Name.Local == "link" && start.Name.Space == ""):
+			var value string
+			if err := decoder.DecodeElement(&value, &start); err != nil {
+				return nil, err
+			}
+			if start.Name.Local == "base_site_url" {
+				export.SiteURL = strings.TrimSpace(value)
+			} else if channelLink == "" {
+				channelLink = strings.TrimSpace(value)
+			}
+		case start.Name.Local == "category" && start.Name.Space != "":
+			var category WXRCategory
+			if err := decoder.DecodeElement(&category, &start); err != nil {
+				return nil, err
+			}
+			category.Nicename = strings.TrimSpace(category.Nicename)
+			category.Name = strings.TrimSpace(category.Name)
+			export.Categories = append(export.Categories, category)
+		case start.Name.Local == "item":
+			var item wxrItem
+			if err := decoder.DecodeElement(&item, &start); err != nil {
+				return nil, err
+			}
+			if item.PostType == "post" {
+				export.Posts = append(export.Posts, item.post())
+			}
+		}
+	}
+
+	if export.SiteURL == "" {
+		export.SiteURL = channelLink
+	}
+
+	if export.SiteURL == "" {
+		return nil, errors.New("not a WordPress export: no site URL found")
+	}
+
+	return export, nil
+}
+
+func (item *wxrItem) post() WXRPost {
+	post := WXRPost{
+		ID:      item.PostID,
+		Title:   strings.TrimSpace(item.Title),
+		Link:    strings.TrimSpace(item.Link),
+		GUID:    strings.TrimSpace(item.GUID),
+		Status:  strings.TrimSpace(item.Status),
+		Content: item.Content,
+	}
+
+	if name, err := url.PathUnescape(strings.TrimSpace(item.PostName)); err == nil {
+		post.Name = name
+	}
+
+	for _, value := range []string{item.DateGMT, item.Date} {
+		if date, err := time.Parse(wxrDateLayout, strings.TrimSpace(value)); err == nil {
+			post.Date = date
+			break
+		}
+	}
+
+	for _, term := range item.Terms {
+		term.Name = strings.TrimSpace(term.Name)
+		switch term.Domain {
+		case "category":
+			post.Categories = append(post.Categories, term)
+		case "post_tag":
+			post.Tags = append(post.Tags, term.Name)
+		}
+	}
+
+	return post
+}
+
+var hrefPattern = regexp.MustCompile(`(?i)(href\s*=\s*)(["'])([^"']*)(["'])`)
+
+// RewriteLinks replaces every href whose target is found in links. Keys
+// must be normalised with NormalizeURL; relative links are resolved against
+// siteURL first.
+func RewriteLinks(html string, siteURL string, links map[string]string) string {
+	base, _ := url.Parse(siteURL)
+	return hrefPattern.ReplaceAllStringFunc(html, func(match string) string {
+		parts := hrefPattern.FindStringSubmatch(match)
+		target := parts[3]
+		if base != nil {
+			if ref, err := url.Parse(target); err == nil {
+				target = base.ResolveReference(ref).String()
+			}
+		}
+
+		replacement, ok := links[NormalizeURL(target)]
+		if !ok {
+			return match
+		}
+		return parts[1] + parts[2] + replacement + parts[4]
+	})
+}
+
+// NormalizeURL reduces a URL to host, path and the "p" or "cat" query
+// parameter so that http/https, www. and trailing-slash variants of a
+// WordPress link compare equal.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	key := strings.TrimPrefix(strings.ToLower(u.Host), "www.") + strings.TrimSuffix(u.EscapedPath(), "/")
+	query := u.Query()
+	for _, param := range []string{"p", "cat"} {
+		if value := query.Get(param); value != "" {
+			key += "?" + param + "=" + value
+		}
+	}
+	return key
+}
+
+// PostURLs lists the URLs under which WordPress served a post.
+func PostURLs(siteURL string, post WXRPost) []string {
+	urls := []string{post.Link, strings.TrimSuffix(siteURL, "/") + "/?p=" + strconv.FormatInt(post.ID, 10)}
+	if strings.Contains(post.GUID, "://") {
+		urls = append(urls, post.GUID)
+	}
+	return urls
+}
+
+// CategoryURLs lists the URLs of a category archive on the WordPress site.
+func CategoryURLs(siteURL string, category WXRCategory) []string {
+	return []string{strings.TrimSuffix(siteURL, "/") + "/category/" + category.Nicename + "/"}
+}
+
+var blockPattern = regexp.MustCompile(`(?i)^\s*<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|img|iframe|!--)\b`)
+
+// Autop wraps the paragraphs of classic WordPress content, which relies on
+// blank lines instead of <p> tags, and turns single line breaks into <br>.
+func Autop(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var out []string
+	for _, block := range strings.Split(content, "\n\n") {
+		block = strings.TrimSpace(block)
+		switch {
+		case block == "":
+			continue
+		case blockPattern.MatchString(block):
+			out = append(out, block)
+		default:
+			out = append(out, "<p>"+strings.ReplaceAll(block, "\n", "<br>\n")+"</p>")
+		}
+	}
+	return strings.Join(out, "\n")
+}
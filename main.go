@@ -4,16 +4,26 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/cdn"
 	"github.com/muhammadrijalkamal/backendtest/controller"
+	"github.com/muhammadrijalkamal/backendtest/feed"
+	"github.com/muhammadrijalkamal/backendtest/graph"
+	"github.com/muhammadrijalkamal/backendtest/imaging"
 	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/outbox"
 	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/rpc"
 	"github.com/muhammadrijalkamal/backendtest/service"
+	"github.com/muhammadrijalkamal/backendtest/storage"
 )
 
 var (
@@ -22,6 +32,9 @@ var (
 	dbUser     = os.Getenv("DB_USER")
 	dbPass     = os.Getenv("DB_PASS")
 	dbName     = os.Getenv("DB_NAME")
+	baseURL    = os.Getenv("BASE_URL")
+	feedTitle  = getenv("FEED_TITLE", "Articles")
+	adminToken = os.Getenv("ADMIN_TOKEN")
 	Connection *sql.DB
 )
 
@@ -46,19 +59,162 @@ func init() {
 }
 
 func main() {
-	articleRepository := repository.NewArticleRepository(Connection)
-	articleService := service.NewArticleService(&articleRepository)
-	articleController := controller.NewArticleController(&articleService)
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	blobStore, err := storage.NewLocalBlobStore(getenv("MEDIA_DIR", "media"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	maxUploadSize, err := strconv.ParseInt(getenv("MEDIA_MAX_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imageSizes := imaging.DefaultSizes
+	if spec := os.Getenv("IMAGE_SIZES"); spec != "" {
+		imageSizes, err = imaging.ParseSizes(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
+	articleRepository := repository.NewArticleRepository(Connection, blobStore)
 	categoryRepository := repository.NewCategoryRepository(Connection)
+	commentRepository := repository.NewCommentRepository(Connection)
+
+	cacheSize, err := strconv.Atoi(getenv("CACHE_SIZE", "10000"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cacheCounters := &cache.Counters{}
+	cacheTTL, err := time.ParseDuration(getenv("CACHE_TTL", "1m"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lruCache := cache.NewLRU(cacheSize, cacheTTL)
+	cacheController := controller.NewCacheController(lruCache, cacheCounters)
+	if cacheSize > 0 {
+		uncachedArticleRepository := articleRepository
+		articleRepository = repository.NewCachedArticleRepository(uncachedArticleRepository, lruCache, cacheCounters)
+		categoryRepository = repository.NewCachedCategoryRepository(categoryRepository, uncachedArticleRepository, lruCache, cacheCounters)
+		commentRepository = repository.NewCachedCommentRepository(commentRepository, lruCache, cacheCounters)
+	}
+
+	sitemapService := service.NewSitemapService(&articleRepository, &categoryRepository)
+	sitemapController := controller.NewSitemapController(&sitemapService, baseURL)
+
+	webhookRepository := repository.NewWebhookRepository(Connection)
+	webhookService := service.NewWebhookService(&webhookRepository)
+	webhookController := controller.NewWebhookController(&webhookService, adminToken)
+
+	eventStreamService := service.NewEventStreamService()
+	eventStreamController := controller.NewEventStreamController(&eventStreamService)
+
+	subscribers := []outbox.Subscriber{sitemapService, webhookService, eventStreamService}
+	if purgeURL := os.Getenv("CDN_PURGE_URL"); purgeURL != "" {
+		purger := cdn.NewPurger(purgeURL, getenv("CDN_PURGE_TOKEN_HEADER", "Fastly-Key"), os.Getenv("CDN_PURGE_TOKEN"))
+		subscribers = append(subscribers, purger)
+	}
+
+	var publisher outbox.EventPublisher = outbox.NewInProcessPublisher(subscribers...)
+	if eventLogFile := os.Getenv("EVENT_LOG_FILE"); eventLogFile != "" {
+		logPublisher, err := outbox.NewLogPublisher(eventLogFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		publisher = outbox.Publishers{publisher, logPublisher}
+	}
+
+	outboxRepository := repository.NewOutboxRepository(Connection)
+	outboxRelay := outbox.NewRelay(&outboxRepository, publisher)
+	go outboxRelay.Run()
+
+	cachePolicy, err := controller.ParseCachePolicy(os.Getenv("HTTP_CACHE_POLICY"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	articleService := service.NewArticleService(&articleRepository, imageSizes)
+	articleController := controller.NewArticleController(&articleService)
+
 	categoryService := service.NewCategoryService(&categoryRepository)
 	categoryController := controller.NewCategoryController(&categoryService)
 
+	commentService := service.NewCommentService(&commentRepository, &articleRepository)
+	commentController := controller.NewCommentController(&commentService, adminToken)
+
+	mediaRepository := repository.NewMediaRepository(Connection)
+	mediaService := service.NewMediaService(&mediaRepository, &articleRepository, blobStore, imageSizes)
+	mediaController := controller.NewMediaController(&mediaService, maxUploadSize)
+
+	importRepository := repository.NewImportRepository(Connection)
+	importService := service.NewImportService(&articleRepository, &importRepository, &categoryService)
+	importController := controller.NewImportController(&importService, adminToken)
+
+	backupRepository := repository.NewBackupRepository(Connection)
+	backupService := service.NewBackupService(&backupRepository, blobStore)
+	exportController := controller.NewExportController(&backupService, adminToken)
+
+	statsRepository := repository.NewStatsRepository(Connection)
+	statsService := service.NewStatsService(&statsRepository)
+	statsController := controller.NewStatsController(&statsService)
+
+	feedService := service.NewFeedService(&articleRepository, feedTitle)
+	// Feed item IDs must not change with the host a feed is fetched from.
+	feedGUIDHost := getenv("FEED_GUID_HOST", feed.Hostname(baseURL))
+	if feedGUIDHost == "" {
+		log.Println("neither FEED_GUID_HOST nor BASE_URL is set, feed item IDs use localhost")
+		feedGUIDHost = "localhost"
+	}
+	feedController := controller.NewFeedController(&feedService, &categoryService, baseURL, feedGUIDHost)
+
+	graphQLMaxDepth, err := strconv.Atoi(getenv("GRAPHQL_MAX_DEPTH", "8"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	graphQLMaxComplexity, err := strconv.Atoi(getenv("GRAPHQL_MAX_COMPLEXITY", "2000"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	graphQLServer, err := graph.NewServer(&articleService, &categoryService, graph.Limits{MaxDepth: graphQLMaxDepth, MaxComplexity: graphQLMaxComplexity})
+	if err != nil {
+		log.Fatal(err)
+	}
+	graphQLController := controller.NewGraphQLController(graphQLServer)
+
+	grpcListener, err := net.Listen("tcp", getenv("GRPC_ADDR", ":50051"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcServer := rpc.NewServer(&articleService, &categoryService)
+	go func() {
+		log.Fatal(grpcServer.Serve(grpcListener))
+	}()
+
+	openAPIDocument := controller.NewOpenAPIDocument()
+	openAPIController := controller.NewOpenAPIController(openAPIDocument)
+
 	app := fiber.New(fiber.Config{
+		BodyLimit: 32 * 1024 * 1024,
 		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
 			if err != nil {
-				return ctx.Status(fiber.StatusBadRequest).JSON(model.ErrorResponse{
-					StatusCode: fiber.StatusBadRequest,
+				code := fiber.StatusBadRequest
+				if e, ok := err.(*fiber.Error); ok {
+					code = e.Code
+				}
+				return controller.Render(ctx, code, model.ErrorResponse{
+					StatusCode: code,
 					Error:      err.Error(),
 				})
 			}
@@ -68,9 +224,30 @@ func main() {
 
 	app.Use(cors.New())
 	app.Use(recover.New())
+	app.Use(controller.RejectUnacceptable())
+	app.Use(controller.ConditionalGET(cachePolicy))
 
 	articleController.SetupRoutes(app)
 	categoryController.SetupRoutes(app)
+	commentController.SetupRoutes(app)
+	mediaController.SetupRoutes(app)
+	importController.SetupRoutes(app)
+	exportController.SetupRoutes(app)
+	statsController.SetupRoutes(app)
+	feedController.SetupRoutes(app)
+	sitemapController.SetupRoutes(app)
+	cacheController.SetupRoutes(app)
+	webhookController.SetupRoutes(app)
+	eventStreamController.SetupRoutes(app)
+	graphQLController.SetupRoutes(app)
+	openAPIController.SetupRoutes(app)
 
 	log.Fatal(app.Listen(":3000"))
 }
+
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -5,26 +5,71 @@ import (
 )
 
 type ArticleCreateRequest struct {
-	Title      string `json:"title"`
-	CategoryID int64  `json:"category_id"`
-	Content    string `json:"content"`
+	Title           string `json:"title" xml:"title"`
+	CategoryID      int64  `json:"category_id" xml:"category_id"`
+	Content         string `json:"content" xml:"content"`
+	ContentFormat   string `json:"content_format" xml:"content_format"`
+	Status          string `json:"status" xml:"status"`
+	FeaturedImageID int64  `json:"featured_image_id" xml:"featured_image_id"`
 }
 
 type ArticleUpdateRequest struct {
-	Title      string `json:"title"`
-	CategoryID int64  `json:"category_id"`
-	Content    string `json:"content"`
+	Title           string `json:"title" xml:"title"`
+	CategoryID      int64  `json:"category_id" xml:"category_id"`
+	Content         string `json:"content" xml:"content"`
+	ContentFormat   string `json:"content_format" xml:"content_format"`
+	Status          string `json:"status" xml:"status"`
+	FeaturedImageID int64  `json:"featured_image_id" xml:"featured_image_id"`
+}
+
+// ArticleFilter narrows and pages a listing of live articles. An empty
+// Status or Title matches every article.
+type ArticleFilter struct {
+	CategoryIDs []int64
+	Status      string
+	Title       string
+	Limit       int
+	Offset      int
+}
+
+type ArticleMoveRequest struct {
+	ArticleIDs []int64 `json:"article_ids"`
+	CategoryID int64   `json:"category_id"`
+}
+
+type ArticleMoveResponse struct {
+	CategoryID int64 `json:"category_id"`
+	Moved      int64 `json:"moved"`
 }
 
 type ArticleResponse struct {
-	ID           int64     `json:"id"`
-	Title        string    `json:"title"`
-	Slug         string    `json:"slug"`
-	CategoryID   int64     `json:"category_id"`
-	CategoryName string    `json:"category_name"`
-	CategorySlug string    `json:"category_slug"`
-	Content      string    `json:"content"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	DeletedAt    time.Time `json:"deleted_at"`
+	ID              int64      `json:"id"`
+	Title           string     `json:"title"`
+	Slug            string     `json:"slug"`
+	CategoryID      int64      `json:"category_id"`
+	CategoryName    string     `json:"category_name"`
+	CategorySlug    string     `json:"category_slug"`
+	Content         string     `json:"content,omitempty"`
+	ContentFormat   string     `json:"content_format"`
+	ContentHTML     string     `json:"content_html,omitempty"`
+	TOC             []TOCEntry `json:"toc,omitempty"`
+	Excerpt         string     `json:"excerpt"`
+	WordCount       int        `json:"word_count"`
+	ReadingTime     int        `json:"reading_time_minutes"`
+	CommentCount    int64      `json:"comment_count"`
+	Status          string     `json:"status"`
+	FeaturedImageID int64      `json:"featured_image_id"`
+	PublishedAt     time.Time  `json:"published_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       time.Time  `json:"deleted_at"`
+
+	FeaturedImage *ImageResponse    `json:"featured_image,omitempty"`
+	Category      *CategoryResponse `json:"category,omitempty"`
+}
+
+type TOCEntry struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
 }
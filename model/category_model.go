@@ -12,11 +12,23 @@ type CategoryUpdateRequest struct {
 	CategoryName string `json:"category_name"`
 }
 
+type CategoryMergeRequest struct {
+	TargetID int64 `json:"target_id"`
+}
+
 type CategoryResponse struct {
-	ID           int64     `json:"id"`
-	CategoryName string    `json:"category_name"`
-	CategorySlug string    `json:"category_slug"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	DeletedAt    time.Time `json:"deleted_at"`
+	ID           int64          `json:"id"`
+	CategoryName string         `json:"category_name"`
+	CategorySlug string         `json:"category_slug"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    time.Time      `json:"deleted_at"`
+	Stats        *CategoryStats `json:"stats,omitempty"`
+}
+
+type CategoryStats struct {
+	LiveArticles    int64      `json:"live_articles"`
+	DraftArticles   int64      `json:"draft_articles"`
+	DeletedArticles int64      `json:"deleted_articles"`
+	LastPublishedAt *time.Time `json:"last_published_at"`
 }
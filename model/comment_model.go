@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+type CommentCreateRequest struct {
+	ParentID    int64  `json:"parent_id"`
+	AuthorName  string `json:"author_name"`
+	AuthorEmail string `json:"author_email"`
+	Body        string `json:"body"`
+}
+
+type CommentModerateRequest struct {
+	Status string `json:"status"`
+}
+
+type CommentResponse struct {
+	ID          int64             `json:"id"`
+	ArticleID   int64             `json:"article_id"`
+	ParentID    int64             `json:"parent_id"`
+	AuthorName  string            `json:"author_name"`
+	AuthorEmail string            `json:"author_email,omitempty"`
+	Body        string            `json:"body"`
+	Status      string            `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	DeletedAt   time.Time         `json:"deleted_at"`
+	Replies     []CommentResponse `json:"replies,omitempty"`
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	EventArticleCreated     = "article.created"
+	EventArticleUpdated     = "article.updated"
+	EventArticleSoftDeleted = "article.soft_deleted"
+	EventArticleDeleted     = "article.deleted"
+	EventArticleMoved       = "article.moved"
+
+	EventCategoryCreated     = "category.created"
+	EventCategoryUpdated     = "category.updated"
+	EventCategorySoftDeleted = "category.soft_deleted"
+	EventCategoryDeleted     = "category.deleted"
+	EventCategoryMerged      = "category.merged"
+
+	EventCommentCreated     = "comment.created"
+	EventCommentModerated   = "comment.moderated"
+	EventCommentSoftDeleted = "comment.soft_deleted"
+	EventCommentDeleted     = "comment.deleted"
+)
+
+var ChangeEventTypes = []string{
+	EventArticleCreated,
+	EventArticleUpdated,
+	EventArticleSoftDeleted,
+	EventArticleDeleted,
+	EventArticleMoved,
+	EventCategoryCreated,
+	EventCategoryUpdated,
+	EventCategorySoftDeleted,
+	EventCategoryDeleted,
+	EventCategoryMerged,
+	EventCommentCreated,
+	EventCommentModerated,
+	EventCommentSoftDeleted,
+	EventCommentDeleted,
+}
+
+// ValidEventPattern reports whether a subscription entry is an event type,
+// "*" or a "<resource>.*" wildcard.
+func ValidEventPattern(pattern string) bool {
+	if pattern == "*" || pattern == "article.*" || pattern == "category.*" || pattern == "comment.*" {
+		return true
+	}
+
+	for _, eventType := range ChangeEventTypes {
+		if eventType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchEventType reports whether an event type is covered by any pattern.
+func MatchEventType(patterns []string, eventType string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeEvent describes a committed write. Events are recorded in the outbox
+// in the same transaction as the write; ID is unique per event so consumers
+// can drop the duplicates at-least-once delivery may produce, and Sequence is
+// the outbox position. Article, Category or Comment holds the row as it was
+// right after the write (or right before a hard delete).
+type ChangeEvent struct {
+	ID         string
+	Sequence   int64
+	Type       string
+	ArticleID  int64
+	CategoryID int64
+	Article    *ArticleResponse
+	Category   *CategoryResponse
+	Comment    *CommentResponse
+	CreatedAt  time.Time
+}
+
+// EventMessage is the wire form of a ChangeEvent used by the event stream
+// and the event log.
+type EventMessage struct {
+	ID         string      `json:"id"`
+	Sequence   int64       `json:"sequence"`
+	Type       string      `json:"type"`
+	ArticleID  int64       `json:"article_id,omitempty"`
+	CategoryID int64       `json:"category_id,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Data       interface{} `json:"data"`
+}
+
+func (event ChangeEvent) Message() EventMessage {
+	message := EventMessage{
+		ID:         event.ID,
+		Sequence:   event.Sequence,
+		Type:       event.Type,
+		ArticleID:  event.ArticleID,
+		CategoryID: event.CategoryID,
+		CreatedAt:  event.CreatedAt,
+		Data:       event.Article,
+	}
+
+	if event.Category != nil {
+		message.Data = event.Category
+	}
+
+	if event.Comment != nil {
+		message.Data = event.Comment
+	}
+
+	return message
+}
@@ -0,0 +1,152 @@
+package model
+
+import "time"
+
+// Tables of an export, in the order they are written and restored. Webhook
+// deliveries and their attempts, and the outbox, are logs of work already
+// done and are left out on purpose.
+const (
+	ExportTableCategories        = "categories"
+	ExportTableCategoryRedirects = "category_redirects"
+	ExportTableMedia             = "media"
+	ExportTableArticles          = "articles"
+	ExportTableAttachments       = "article_attachments"
+	ExportTableComments          = "comments"
+	ExportTableWebhooks          = "webhooks"
+	ExportTableImportMappings    = "import_mappings"
+)
+
+var ExportTables = []string{
+	ExportTableCategories,
+	ExportTableCategoryRedirects,
+	ExportTableMedia,
+	ExportTableArticles,
+	ExportTableAttachments,
+	ExportTableComments,
+	ExportTableWebhooks,
+	ExportTableImportMappings,
+}
+
+// ExportTableSince is the export version a table was added in, for tables
+// that older archives do not have.
+var ExportTableSince = map[string]int{
+	ExportTableComments:       2,
+	ExportTableWebhooks:       2,
+	ExportTableImportMappings: 2,
+}
+
+// ExportCategory and the other export records mirror the table rows, so a
+// restore writes back exactly what was read, IDs and timestamps included.
+type ExportCategory struct {
+	ID           int64      `json:"id"`
+	CategoryName string     `json:"category_name"`
+	CategorySlug string     `json:"category_slug"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+}
+
+type ExportCategoryRedirect struct {
+	OldSlug    string    `json:"old_slug"`
+	CategoryID int64     `json:"category_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ExportMedia struct {
+	ID          int64     `json:"id"`
+	SHA256      string    `json:"sha256"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Filename    string    `json:"filename"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ExportArticle struct {
+	ID              int64      `json:"id"`
+	Title           string     `json:"title"`
+	Slug            string     `json:"slug"`
+	CategoryID      int64      `json:"category_id"`
+	Content         string     `json:"content"`
+	ContentFormat   string     `json:"content_format"`
+	ContentHTML     string     `json:"content_html"`
+	ContentText     string     `json:"content_text"`
+	TOC             string     `json:"toc"`
+	Excerpt         string     `json:"excerpt"`
+	WordCount       int        `json:"word_count"`
+	ReadingTime     int        `json:"reading_time_minutes"`
+	Status          string     `json:"status"`
+	FeaturedImageID *int64     `json:"featured_image_id"`
+	PublishedAt     *time.Time `json:"published_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at"`
+}
+
+type ExportAttachment struct {
+	ArticleID int64     `json:"article_id"`
+	MediaID   int64     `json:"media_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportComment struct {
+	ID          int64      `json:"id"`
+	ArticleID   int64      `json:"article_id"`
+	ParentID    *int64     `json:"parent_id"`
+	AuthorName  string     `json:"author_name"`
+	AuthorEmail string     `json:"author_email"`
+	Body        string     `json:"body"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+type ExportWebhook struct {
+	ID        int64      `json:"id"`
+	URL       string     `json:"url"`
+	Secret    string     `json:"secret"`
+	Events    string     `json:"events"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ExportImportMapping struct {
+	Source     string    `json:"source"`
+	Kind       string    `json:"kind"`
+	ExternalID string    `json:"external_id"`
+	TargetID   int64     `json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewExportRecord returns an empty record of the table, or nil for a table
+// that is not part of an export.
+func NewExportRecord(table string) interface{} {
+	switch table {
+	case ExportTableCategories:
+		return &ExportCategory{}
+	case ExportTableCategoryRedirects:
+		return &ExportCategoryRedirect{}
+	case ExportTableMedia:
+		return &ExportMedia{}
+	case ExportTableArticles:
+		return &ExportArticle{}
+	case ExportTableAttachments:
+		return &ExportAttachment{}
+	case ExportTableComments:
+		return &ExportComment{}
+	case ExportTableWebhooks:
+		return &ExportWebhook{}
+	case ExportTableImportMappings:
+		return &ExportImportMapping{}
+	}
+	return nil
+}
+
+type RestoreReport struct {
+	Version int            `json:"version"`
+	Records map[string]int `json:"records"`
+	Blobs   int            `json:"blobs"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+type FeedSource struct {
+	Title    string
+	Category *CategoryResponse
+	Articles []ArticleResponse
+	Updated  time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+const (
+	ImportActionCreate = "create"
+	ImportActionSkip   = "skip"
+	ImportActionFail   = "fail"
+)
+
+type ImportReport struct {
+	DryRun            bool               `json:"dry_run"`
+	Created           int                `json:"created"`
+	Skipped           int                `json:"skipped"`
+	Failed            int                `json:"failed"`
+	CategoriesCreated []string           `json:"categories_created"`
+	Items             []ImportItemResult `json:"items"`
+}
+
+type ImportItemResult struct {
+	Source   string   `json:"source"`
+	Action   string   `json:"action"`
+	ID       int64    `json:"id,omitempty"`
+	Slug     string   `json:"slug,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"ignored_tags,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
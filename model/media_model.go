@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+type AttachmentRequest struct {
+	MediaID int64 `json:"media_id"`
+}
+
+type MediaResponse struct {
+	ID          int64     `json:"id"`
+	SHA256      string    `json:"sha256"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Filename    string    `json:"filename"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ImageResponse describes an image used by an article together with the
+// URLs of its resized variants.
+type ImageResponse struct {
+	ID       int64             `json:"id"`
+	Width    int               `json:"width"`
+	Height   int               `json:"height"`
+	URL      string            `json:"url"`
+	Srcset   string            `json:"srcset"`
+	Variants map[string]string `json:"variants"`
+}
+
+// MediaURL is the path the content of a media item is served from.
+func MediaURL(mediaID int64) string {
+	return "/media/" + strconv.FormatInt(mediaID, 10) + "/content"
+}
+
+func MediaVariantURL(mediaID int64, size string) string {
+	return "/media/" + strconv.FormatInt(mediaID, 10) + "/variants/" + size
+}
@@ -0,0 +1,42 @@
+package model
+
+import "github.com/muhammadrijalkamal/backendtest/util"
+
+var (
+	ArticleFields = []string{
+		"id", "title", "slug", "category_id", "category_name", "category_slug",
+		"content", "content_format", "content_html", "toc", "excerpt", "word_count", "reading_time_minutes",
+		"comment_count", "status", "featured_image_id", "featured_image", "published_at", "created_at", "updated_at", "deleted_at",
+	}
+
+	ArticleContentFields = []string{"content", "content_html", "toc"}
+
+	ArticleExpansions = []string{"category"}
+
+	// UnsupportedExpansions are relationships clients ask for that the schema
+	// does not have yet, with the reason they are refused.
+	UnsupportedExpansions = map[string]string{
+		"author": "articles have no authors yet",
+		"tags":   "articles have no tags yet",
+	}
+
+	CategoryFields = []string{"id", "category_name", "category_slug", "created_at", "updated_at", "deleted_at"}
+
+	CategoryExpansions = []string{"stats"}
+
+	CategoryStatsFields = []string{"live_articles", "draft_articles", "deleted_articles", "last_published_at"}
+)
+
+type Selection struct {
+	Fields []string
+	Expand []string
+	Sparse bool
+}
+
+func (s *Selection) HasField(field string) bool {
+	return util.Contains(s.Fields, field)
+}
+
+func (s *Selection) Expands(expansion string) bool {
+	return util.Contains(s.Expand, expansion)
+}
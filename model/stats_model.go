@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+type StatsResponse struct {
+	Totals     StatsTotals             `json:"totals"`
+	Categories []CategoryArticleCounts `json:"categories"`
+	Daily      []DailyArticleCount     `json:"daily"`
+	Growth     StatsGrowth             `json:"growth"`
+}
+
+type StatsTotals struct {
+	Categories      int64 `json:"categories"`
+	LiveArticles    int64 `json:"live_articles"`
+	DraftArticles   int64 `json:"draft_articles"`
+	DeletedArticles int64 `json:"deleted_articles"`
+}
+
+type CategoryArticleCounts struct {
+	CategoryID   int64         `json:"category_id"`
+	CategoryName string        `json:"category_name"`
+	CategorySlug string        `json:"category_slug"`
+	Stats        CategoryStats `json:"stats"`
+}
+
+type DailyArticleCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type StatsGrowth struct {
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Current       int64     `json:"current"`
+	Previous      int64     `json:"previous"`
+	Change        int64     `json:"change"`
+	ChangePercent *float64  `json:"change_percent"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type WebhookCreateRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookUpdateRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            int64                    `json:"id"`
+	WebhookID     int64                    `json:"webhook_id"`
+	EventID       string                   `json:"event_id"`
+	EventType     string                   `json:"event_type"`
+	Payload       json.RawMessage          `json:"payload,omitempty"`
+	Status        string                   `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	NextAttemptAt *time.Time               `json:"next_attempt_at"`
+	DeliveredAt   *time.Time               `json:"delivered_at"`
+	CreatedAt     time.Time                `json:"created_at"`
+	AttemptLog    []WebhookAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+type WebhookAttemptResponse struct {
+	ID             int64     `json:"id"`
+	ResponseStatus int       `json:"response_status"`
+	Error          string    `json:"error"`
+	DurationMS     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the signed body POSTed to subscribers. Data holds the
+// ArticleResponse or CategoryResponse snapshot the event is about.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var fiberParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+// Route describes one route in Fiber's path syntax. Successful responses
+// are envelopes around Data, unless Produces lists the raw content types the
+// route answers with instead. Alternatives are raw content types offered
+// next to the envelope, such as CSV for a listing. Upload routes take a
+// multipart form with a single "file" field.
+type Route struct {
+	ID           string
+	Method       string
+	Path         string
+	Tag          string
+	Summary      string
+	Description  string
+	Query        []Param
+	Headers      []Param
+	Body         interface{}
+	BodyTypes    []string
+	Status       int
+	Data         interface{}
+	Produces     []string
+	Alternatives []string
+	Upload       bool
+	Admin        bool
+}
+
+type Param struct {
+	Name        string
+	Type        string
+	Description string
+	Enum        []string
+	Required    bool
+}
+
+// New starts a document whose envelopes are success and failure, rendered
+// in any of contentTypes.
+func New(info Info, success interface{}, failure interface{}, contentTypes ...string) *Document {
+	d := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	d.success = d.SchemaOf(success)
+	d.failure = d.SchemaOf(failure)
+	d.contentTypes = contentTypes
+	return d
+}
+
+// Path converts a Fiber route path such as "/article/:id" to its OpenAPI
+// form, "/article/{id}".
+func Path(fiberPath string) string {
+	return fiberParamPattern.ReplaceAllString(fiberPath, "{$1}")
+}
+
+func (d *Document) Add(route Route) {
+	operation := &Operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]Response{},
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	for _, match := range fiberParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: paramSchema(Param{Type: pathParamType(match[1])})})
+	}
+	for _, param := range route.Query {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: paramSchema(param)})
+	}
+	for _, param := range route.Headers {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: param.Name, In: "header", Description: param.Description, Required: param.Required, Schema: paramSchema(param)})
+	}
+
+	if route.Body != nil {
+		bodyTypes := route.BodyTypes
+		if len(bodyTypes) == 0 {
+			bodyTypes = []string{"application/json"}
+		}
+		schema := d.SchemaOf(route.Body)
+		operation.RequestBody = &RequestBody{Required: true, Content: content(schema, bodyTypes)}
+	}
+	if route.Upload {
+		form := &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"file": {Type: "string", ContentMediaType: "application/octet-stream"}},
+			Required:   []string{"file"},
+		}
+		operation.RequestBody = &RequestBody{Required: true, Content: content(form, []string{"multipart/form-data"})}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := Response{Description: http.StatusText(status)}
+	if len(route.Produces) > 0 {
+		success.Content = content(&Schema{Type: "string"}, route.Produces)
+	} else {
+		schema := &Schema{AllOf: []*Schema{d.success, {Type: "object", Properties: map[string]*Schema{"data": d.SchemaOf(route.Data)}}}}
+		success.Content = content(schema, d.contentTypes)
+	}
+	for _, contentType := range route.Alternatives {
+		success.Content[contentType] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+	operation.Responses["default"] = Response{Description: "Error", Content: content(d.failure, d.contentTypes)}
+
+	if route.Admin {
+		if d.Components.SecuritySchemes == nil {
+			d.Components.SecuritySchemes = map[string]SecurityScheme{"adminToken": {Type: "http", Scheme: "bearer"}}
+		}
+		operation.Security = []map[string][]string{{"adminToken": {}}}
+	}
+
+	d.addTag(route.Tag)
+	path := Path(route.Path)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	*item.operation(route.Method) = operation
+}
+
+// Has reports whether the document describes the method and Fiber path.
+func (d *Document) Has(method string, fiberPath string) bool {
+	item, ok := d.Paths[Path(fiberPath)]
+	if !ok {
+		return false
+	}
+
+	operation := item.operation(method)
+	return operation != nil && *operation != nil
+}
+
+func (item *PathItem) operation(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return &item.Get
+	case http.MethodPut:
+		return &item.Put
+	case http.MethodPost:
+		return &item.Post
+	case http.MethodDelete:
+		return &item.Delete
+	case http.MethodPatch:
+		return &item.Patch
+	}
+	return nil
+}
+
+func (d *Document) addTag(name string) {
+	if name == "" {
+		return
+	}
+	for _, tag := range d.Tags {
+		if tag.Name == name {
+			return
+		}
+	}
+	d.Tags = append(d.Tags, Tag{Name: name})
+}
+
+func content(schema *Schema, contentTypes []string) map[string]MediaType {
+	media := make(map[string]MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		media[contentType] = MediaType{Schema: schema}
+	}
+	return media
+}
+
+// pathParamType treats "id" and names ending in "Id" as integer IDs.
+func pathParamType(name string) string {
+	if name == "id" || strings.HasSuffix(name, "Id") {
+		return "integer"
+	}
+	return "string"
+}
+
+func paramSchema(param Param) *Schema {
+	schema := &Schema{Type: param.Type, Enum: param.Enum}
+	if param.Type == "" {
+		schema.Type = "string"
+	}
+	if param.Type == "integer" {
+		schema.Format = "int64"
+	}
+	return schema
+}
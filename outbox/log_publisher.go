@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// LogPublisher appends each event as a JSON line to a file, which makes the
+// published stream easy to inspect and assert on in tests.
+type LogPublisher struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func NewLogPublisher(path string) (*LogPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogPublisher{file: file}, nil
+}
+
+func (publisher *LogPublisher) Publish(event model.ChangeEvent) error {
+	line, err := json.Marshal(event.Message())
+	if err != nil {
+		return err
+	}
+
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	_, err = publisher.file.Write(append(line, '\n'))
+	return err
+}
+
+func (publisher *LogPublisher) Close() error {
+	return publisher.file.Close()
+}
@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"fmt"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// EventPublisher receives every outbox event in order. Publish may see the
+// same event more than once (e.g. after a crash before the relay recorded
+// it as published); ChangeEvent.ID identifies duplicates.
+type EventPublisher interface {
+	Publish(event model.ChangeEvent) error
+}
+
+// Publishers fans an event out to several publishers, stopping at the first
+// error so the relay retries the event later.
+type Publishers []EventPublisher
+
+func (publishers Publishers) Publish(event model.ChangeEvent) error {
+	for _, publisher := range publishers {
+		if err := publisher.Publish(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Subscriber interface {
+	OnChange(event model.ChangeEvent)
+}
+
+const recentEventIDs = 10000
+
+// InProcessPublisher hands events to subscribers living in this process and
+// drops events it has already delivered recently. A subscriber that panics
+// fails the publish, so the relay offers the event to every subscriber again
+// later; subscribers must therefore tolerate repeats.
+type InProcessPublisher struct {
+	subscribers []Subscriber
+	seen        map[string]bool
+	order       []string
+}
+
+func NewInProcessPublisher(subscribers ...Subscriber) *InProcessPublisher {
+	return &InProcessPublisher{
+		subscribers: subscribers,
+		seen:        map[string]bool{},
+	}
+}
+
+// Publish is only called from the relay goroutine, so it needs no locking.
+func (publisher *InProcessPublisher) Publish(event model.ChangeEvent) error {
+	if publisher.seen[event.ID] {
+		return nil
+	}
+
+	var failure error
+	for _, subscriber := range publisher.subscribers {
+		if err := notify(subscriber, event); err != nil && failure == nil {
+			failure = err
+		}
+	}
+
+	if failure != nil {
+		return failure
+	}
+
+	publisher.seen[event.ID] = true
+	publisher.order = append(publisher.order, event.ID)
+	if len(publisher.order) > recentEventIDs {
+		delete(publisher.seen, publisher.order[0])
+		publisher.order = publisher.order[1:]
+	}
+
+	return nil
+}
+
+func notify(subscriber Subscriber, event model.ChangeEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%T failed on %s %s: %v", subscriber, event.Type, event.ID, r)
+		}
+	}()
+
+	subscriber.OnChange(event)
+	return nil
+}
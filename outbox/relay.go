@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"log"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/repository"
+)
+
+const (
+	relayBatchSize     = 100
+	relayPollInterval  = 500 * time.Millisecond
+	relayPruneInterval = time.Hour
+	relayRetention     = 7 * 24 * time.Hour
+)
+
+// Relay moves committed outbox rows to the publisher in outbox order and
+// marks them published afterwards, so every event is delivered at least once.
+type Relay struct {
+	outboxRepository repository.OutboxRepository
+	publisher        EventPublisher
+}
+
+func NewRelay(outboxRepo *repository.OutboxRepository, publisher EventPublisher) *Relay {
+	return &Relay{
+		outboxRepository: *outboxRepo,
+		publisher:        publisher,
+	}
+}
+
+func (relay *Relay) Run() {
+	poll := time.NewTicker(relayPollInterval)
+	defer poll.Stop()
+
+	prune := time.NewTicker(relayPruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-poll.C:
+			for {
+				relayed, err := relay.relayBatch()
+				if err != nil {
+					log.Printf("outbox: relaying events: %v", err)
+				}
+				if err != nil || relayed < relayBatchSize {
+					break
+				}
+			}
+		case <-prune.C:
+			if _, err := relay.outboxRepository.DeletePublishedOlderThan(relayRetention); err != nil {
+				log.Printf("outbox: pruning published events: %v", err)
+			}
+		}
+	}
+}
+
+func (relay *Relay) relayBatch() (int, error) {
+	events, err := relay.outboxRepository.FindUnpublished(relayBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(*events))
+	for _, event := range *events {
+		// Stop at the first failure; later events wait so the order holds.
+		if err := relay.publisher.Publish(event); err != nil {
+			relay.markPublished(published)
+			return len(published), err
+		}
+		published = append(published, event.Sequence)
+	}
+
+	return len(published), relay.outboxRepository.MarkPublished(published)
+}
+
+func (relay *Relay) markPublished(sequences []int64) {
+	if err := relay.outboxRepository.MarkPublished(sequences); err != nil {
+		log.Printf("outbox: marking events published: %v", err)
+	}
+}
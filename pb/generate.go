@@ -0,0 +1,5 @@
+// Package pb holds the code generated from the protobuf definitions under
+// proto/. Regenerate it with go generate after changing them.
+package pb
+
+//go:generate protoc -I ../proto --go_out=.. --go_opt=module=github.com/muhammadrijalkamal/backendtest --go-grpc_out=.. --go-grpc_opt=module=github.com/muhammadrijalkamal/backendtest backendtest/v1/article.proto backendtest/v1/category.proto
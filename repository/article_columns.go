@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type articleColumn struct {
+	expr string
+	bind func(article *model.ArticleResponse) (interface{}, func() error)
+}
+
+// articleColumns maps every selectable response field to the SQL expression
+// it is read from, so a query only selects and joins what was asked for.
+var articleColumns = map[string]articleColumn{
+	"id":                   {expr: "a.id", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.ID })},
+	"title":                {expr: "a.title", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.Title })},
+	"slug":                 {expr: "a.slug", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.Slug })},
+	"category_id":          {expr: "a.category_id", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.CategoryID })},
+	"category_name":        {expr: "c.category_name", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.CategoryName })},
+	"category_slug":        {expr: "c.category_slug", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.CategorySlug })},
+	"content":              {expr: "a.content", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.Content })},
+	"content_format":       {expr: "a.content_format", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.ContentFormat })},
+	"content_html":         {expr: "a.content_html", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.ContentHTML })},
+	"toc":                  {expr: "a.toc", bind: bindTOC},
+	"excerpt":              {expr: "a.excerpt", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.Excerpt })},
+	"word_count":           {expr: "a.word_count", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.WordCount })},
+	"reading_time_minutes": {expr: "a.reading_time_minutes", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.ReadingTime })},
+	"comment_count":        {expr: "(SELECT COUNT(*) FROM comments AS cm WHERE cm.article_id = a.id AND cm.status = 'approved' AND cm.deleted_at IS NULL)", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.CommentCount })},
+	"status":               {expr: "a.status", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.Status })},
+	"featured_image_id":    {expr: "a.featured_image_id", bind: bindNullInt64(func(a *model.ArticleResponse) *int64 { return &a.FeaturedImageID })},
+	"featured_image":       {expr: "(SELECT JSON_OBJECT('id', m.id, 'width', m.width, 'height', m.height) FROM media AS m WHERE m.id = a.featured_image_id)", bind: bindImage},
+	"published_at":         {expr: "a.published_at", bind: bindNullTime(func(a *model.ArticleResponse) *time.Time { return &a.PublishedAt })},
+	"created_at":           {expr: "a.created_at", bind: bindValue(func(a *model.ArticleResponse) interface{} { return &a.CreatedAt })},
+	"updated_at":           {expr: "a.updated_at", bind: bindNullTime(func(a *model.ArticleResponse) *time.Time { return &a.UpdatedAt })},
+	"deleted_at":           {expr: "a.deleted_at", bind: bindNullTime(func(a *model.ArticleResponse) *time.Time { return &a.DeletedAt })},
+}
+
+const articleCategoryColumns = "c.id, c.category_name, c.category_slug, c.created_at, c.updated_at, c.deleted_at"
+
+// articleFrom always joins the category, whatever is selected, so that the
+// projection never changes which rows match: articles whose category was
+// deleted for good are left out of every listing and count alike.
+const articleFrom = " FROM articles AS a INNER JOIN categories AS c ON a.category_id = c.id"
+
+// articleSelect builds the SELECT for the requested fields and expansions. A
+// nil selection reads every field, which is what internal callers rely on.
+func articleSelect(selection *model.Selection) string {
+	fields := articleSelectionFields(selection)
+
+	exprs := make([]string, 0, len(fields)+6)
+	for _, field := range fields {
+		exprs = append(exprs, articleColumns[field].expr)
+	}
+
+	if selection != nil && selection.Expands("category") {
+		exprs = append(exprs, articleCategoryColumns)
+	}
+
+	return "SELECT " + strings.Join(exprs, ", ") + articleFrom
+}
+
+func articleSelectionFields(selection *model.Selection) []string {
+	if selection == nil || len(selection.Fields) == 0 {
+		return model.ArticleFields
+	}
+
+	return selection.Fields
+}
+
+func scanArticles(rows *sql.Rows, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	var articles []model.ArticleResponse
+	for rows.Next() {
+		article, err := scanArticle(rows, selection)
+		if err != nil {
+			return nil, err
+		}
+
+		articles = append(articles, *article)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &articles, nil
+}
+
+func scanArticle(rows *sql.Rows, selection *model.Selection) (*model.ArticleResponse, error) {
+	var article model.ArticleResponse
+
+	fields := articleSelectionFields(selection)
+	dest := make([]interface{}, 0, len(fields)+6)
+	var applies []func() error
+	for _, field := range fields {
+		value, apply := articleColumns[field].bind(&article)
+		dest = append(dest, value)
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+
+	var categoryUpdatedAt, categoryDeletedAt sql.NullTime
+	if selection != nil && selection.Expands("category") {
+		article.Category = &model.CategoryResponse{}
+		dest = append(dest,
+			&article.Category.ID,
+			&article.Category.CategoryName,
+			&article.Category.CategorySlug,
+			&article.Category.CreatedAt,
+			&categoryUpdatedAt,
+			&categoryDeletedAt,
+		)
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	for _, apply := range applies {
+		if err := apply(); err != nil {
+			return nil, err
+		}
+	}
+
+	if article.Category != nil {
+		if categoryUpdatedAt.Valid {
+			article.Category.UpdatedAt = categoryUpdatedAt.Time
+		}
+
+		if categoryDeletedAt.Valid {
+			article.Category.DeletedAt = categoryDeletedAt.Time
+		}
+	}
+
+	return &article, nil
+}
+
+func bindValue(field func(article *model.ArticleResponse) interface{}) func(*model.ArticleResponse) (interface{}, func() error) {
+	return func(article *model.ArticleResponse) (interface{}, func() error) {
+		return field(article), nil
+	}
+}
+
+func bindNullTime(field func(article *model.ArticleResponse) *time.Time) func(*model.ArticleResponse) (interface{}, func() error) {
+	return func(article *model.ArticleResponse) (interface{}, func() error) {
+		var value sql.NullTime
+		return &value, func() error {
+			if value.Valid {
+				*field(article) = value.Time
+			}
+			return nil
+		}
+	}
+}
+
+func bindNullInt64(field func(article *model.ArticleResponse) *int64) func(*model.ArticleResponse) (interface{}, func() error) {
+	return func(article *model.ArticleResponse) (interface{}, func() error) {
+		var value sql.NullInt64
+		return &value, func() error {
+			*field(article) = value.Int64
+			return nil
+		}
+	}
+}
+
+func bindImage(article *model.ArticleResponse) (interface{}, func() error) {
+	var value sql.NullString
+	return &value, func() error {
+		if !value.Valid {
+			return nil
+		}
+		article.FeaturedImage = &model.ImageResponse{}
+		return json.Unmarshal([]byte(value.String), article.FeaturedImage)
+	}
+}
+
+func bindTOC(article *model.ArticleResponse) (interface{}, func() error) {
+	var value string
+	return &value, func() error {
+		if value == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(value), &article.TOC)
+	}
+}
@@ -6,19 +6,41 @@ import (
 )
 
 type ArticleRepository interface {
-	Insert(request *entity.Article) error
+	Insert(request *entity.Article) (int64, error)
 
-	FindAll() (*[]model.ArticleResponse, error)
+	InsertMany(requests []entity.Article) ([]int64, error)
 
-	FindAllByTitle(title string) (*[]model.ArticleResponse, error)
+	FindAll(selection *model.Selection) (*[]model.ArticleResponse, error)
 
-	FindAllSoftDeleted() (*[]model.ArticleResponse, error)
+	FindAllByTitle(title string, selection *model.Selection) (*[]model.ArticleResponse, error)
 
-	FindByID(articleID int64) (*model.ArticleResponse, error)
+	ForEach(title string, selection *model.Selection, fn func(article *model.ArticleResponse) error) error
+
+	FindPage(filter *model.ArticleFilter, selection *model.Selection) (*[]model.ArticleResponse, error)
+
+	FindPageByCategories(categoryIDs []int64, filter *model.ArticleFilter, selection *model.Selection) (map[int64][]model.ArticleResponse, error)
+
+	Count(filter *model.ArticleFilter) (int64, error)
+
+	FindAllPublished(categoryID int64, limit int, selection *model.Selection) (*[]model.ArticleResponse, error)
+
+	FindAllSoftDeleted(selection *model.Selection) (*[]model.ArticleResponse, error)
+
+	FindByID(articleID int64, selection *model.Selection) (*model.ArticleResponse, error)
+
+	FindIDsByCategory(categoryID int64) ([]int64, error)
+
+	FindIDsBySlugs(slugs []string) (map[string]int64, error)
 
 	Update(articleID int64, request *entity.Article) error
 
 	SoftDelete(articleID int64) error
 
 	Delete(articleID int64) error
+
+	SoftDeleteMany(articleIDs []int64, atomic bool) ([]int64, error)
+
+	DeleteMany(articleIDs []int64, atomic bool) ([]int64, error)
+
+	MoveToCategory(articleIDs []int64, categoryID int64) (int64, error)
 }
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"strconv"
+
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type CachedArticleRepository struct {
+	ArticleRepository
+	cacheAside
+}
+
+func NewCachedArticleRepository(repo ArticleRepository, c cache.Cache, counters *cache.Counters) ArticleRepository {
+	return &CachedArticleRepository{
+		ArticleRepository: repo,
+		cacheAside:        cacheAside{cache: c, counters: counters},
+	}
+}
+
+func (r *CachedArticleRepository) Insert(request *entity.Article) (int64, error) {
+	id, err := r.ArticleRepository.Insert(request)
+	r.invalidateArticles([]int64{id})
+	return id, err
+}
+
+func (r *CachedArticleRepository) InsertMany(requests []entity.Article) ([]int64, error) {
+	ids, err := r.ArticleRepository.InsertMany(requests)
+	r.invalidateArticles(ids)
+	return ids, err
+}
+
+func (r *CachedArticleRepository) FindAll(selection *model.Selection) (*[]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "all", selectionKey(selection))
+	var articles *[]model.ArticleResponse
+	if r.get(key, &articles) {
+		return articles, nil
+	}
+
+	articles, err := r.ArticleRepository.FindAll(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, articles)
+	return articles, nil
+}
+
+func (r *CachedArticleRepository) FindAllByTitle(title string, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "title", strconv.Quote(title), selectionKey(selection))
+	var articles *[]model.ArticleResponse
+	if r.get(key, &articles) {
+		return articles, nil
+	}
+
+	articles, err := r.ArticleRepository.FindAllByTitle(title, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, articles)
+	return articles, nil
+}
+
+func (r *CachedArticleRepository) FindAllPublished(categoryID int64, limit int, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "published", strconv.FormatInt(categoryID, 10), strconv.Itoa(limit), selectionKey(selection))
+	var articles *[]model.ArticleResponse
+	if r.get(key, &articles) {
+		return articles, nil
+	}
+
+	articles, err := r.ArticleRepository.FindAllPublished(categoryID, limit, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, articles)
+	return articles, nil
+}
+
+func (r *CachedArticleRepository) FindPage(filter *model.ArticleFilter, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "page", filterKey(filter), selectionKey(selection))
+	var articles *[]model.ArticleResponse
+	if r.get(key, &articles) {
+		return articles, nil
+	}
+
+	articles, err := r.ArticleRepository.FindPage(filter, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, articles)
+	return articles, nil
+}
+
+func (r *CachedArticleRepository) FindPageByCategories(categoryIDs []int64, filter *model.ArticleFilter, selection *model.Selection) (map[int64][]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "categories", idsKey(categoryIDs), filterKey(filter), selectionKey(selection))
+	var grouped map[int64][]model.ArticleResponse
+	if r.get(key, &grouped) {
+		return grouped, nil
+	}
+
+	grouped, err := r.ArticleRepository.FindPageByCategories(categoryIDs, filter, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, grouped)
+	return grouped, nil
+}
+
+func (r *CachedArticleRepository) Count(filter *model.ArticleFilter) (int64, error) {
+	key := r.key(articleListGroup, "count", filterKey(filter))
+	var count int64
+	if r.get(key, &count) {
+		return count, nil
+	}
+
+	count, err := r.ArticleRepository.Count(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.set(key, count)
+	return count, nil
+}
+
+func (r *CachedArticleRepository) FindAllSoftDeleted(selection *model.Selection) (*[]model.ArticleResponse, error) {
+	key := r.key(articleListGroup, "deleted", selectionKey(selection))
+	var articles *[]model.ArticleResponse
+	if r.get(key, &articles) {
+		return articles, nil
+	}
+
+	articles, err := r.ArticleRepository.FindAllSoftDeleted(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, articles)
+	return articles, nil
+}
+
+func (r *CachedArticleRepository) FindByID(articleID int64, selection *model.Selection) (*model.ArticleResponse, error) {
+	key := r.key(articleGroup(articleID), selectionKey(selection))
+	var article *model.ArticleResponse
+	if r.get(key, &article) {
+		return article, nil
+	}
+
+	article, err := r.ArticleRepository.FindByID(articleID, selection)
+	if err != nil || article == nil {
+		return article, err
+	}
+
+	r.set(key, article)
+	return article, nil
+}
+
+func (r *CachedArticleRepository) Update(articleID int64, request *entity.Article) error {
+	err := r.ArticleRepository.Update(articleID, request)
+	r.invalidateArticles([]int64{articleID})
+	return err
+}
+
+func (r *CachedArticleRepository) SoftDelete(articleID int64) error {
+	err := r.ArticleRepository.SoftDelete(articleID)
+	r.invalidateArticles([]int64{articleID})
+	return err
+}
+
+func (r *CachedArticleRepository) Delete(articleID int64) error {
+	err := r.ArticleRepository.Delete(articleID)
+	r.invalidateArticles([]int64{articleID})
+	return err
+}
+
+func (r *CachedArticleRepository) SoftDeleteMany(articleIDs []int64, atomic bool) ([]int64, error) {
+	deleted, err := r.ArticleRepository.SoftDeleteMany(articleIDs, atomic)
+	r.invalidateArticles(articleIDs)
+	return deleted, err
+}
+
+func (r *CachedArticleRepository) DeleteMany(articleIDs []int64, atomic bool) ([]int64, error) {
+	deleted, err := r.ArticleRepository.DeleteMany(articleIDs, atomic)
+	r.invalidateArticles(articleIDs)
+	return deleted, err
+}
+
+func (r *CachedArticleRepository) MoveToCategory(articleIDs []int64, categoryID int64) (int64, error) {
+	moved, err := r.ArticleRepository.MoveToCategory(articleIDs, categoryID)
+	r.invalidateArticles(articleIDs)
+	return moved, err
+}
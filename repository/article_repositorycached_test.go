@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// fakeStore holds the rows the fake repositories read and write. Tests also
+// change it directly, the way another process would, to tell a cached read
+// from a fresh one.
+type fakeStore struct {
+	articles   map[int64]model.ArticleResponse
+	categories map[int64]model.CategoryResponse
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		articles: map[int64]model.ArticleResponse{
+			1: {ID: 1, Title: "First", CategoryID: 1},
+			2: {ID: 2, Title: "Second", CategoryID: 1},
+			3: {ID: 3, Title: "Third", CategoryID: 2},
+		},
+		categories: map[int64]model.CategoryResponse{
+			1: {ID: 1, CategoryName: "News", CategorySlug: "news"},
+			2: {ID: 2, CategoryName: "Sports", CategorySlug: "sports"},
+		},
+	}
+}
+
+// article returns the article as the database would, with the name and slug
+// of its category joined in.
+func (s *fakeStore) article(articleID int64) *model.ArticleResponse {
+	article, ok := s.articles[articleID]
+	if !ok {
+		return nil
+	}
+
+	category := s.categories[article.CategoryID]
+	article.CategoryName, article.CategorySlug = category.CategoryName, category.CategorySlug
+	return &article
+}
+
+type fakeArticleRepository struct {
+	ArticleRepository
+	store *fakeStore
+}
+
+func (r *fakeArticleRepository) FindByID(articleID int64, selection *model.Selection) (*model.ArticleResponse, error) {
+	return r.store.article(articleID), nil
+}
+
+func (r *fakeArticleRepository) FindPage(filter *model.ArticleFilter, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	articles := []model.ArticleResponse{}
+	for articleID := range r.store.articles {
+		article := r.store.article(articleID)
+		if len(filter.CategoryIDs) == 0 || containsID(filter.CategoryIDs, article.CategoryID) {
+			articles = append(articles, *article)
+		}
+	}
+
+	sort.Slice(articles, func(i, j int) bool { return articles[i].ID < articles[j].ID })
+	return &articles, nil
+}
+
+func (r *fakeArticleRepository) FindIDsByCategory(categoryID int64) ([]int64, error) {
+	var ids []int64
+	for _, article := range r.store.articles {
+		if article.CategoryID == categoryID {
+			ids = append(ids, article.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeArticleRepository) Update(articleID int64, request *entity.Article) error {
+	article := r.store.articles[articleID]
+	article.Title, article.CategoryID = request.Title, request.CategoryID
+	r.store.articles[articleID] = article
+	return nil
+}
+
+func (r *fakeArticleRepository) MoveToCategory(articleIDs []int64, categoryID int64) (int64, error) {
+	for _, articleID := range articleIDs {
+		article := r.store.articles[articleID]
+		article.CategoryID = categoryID
+		r.store.articles[articleID] = article
+	}
+	return int64(len(articleIDs)), nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func newCachedArticles(store *fakeStore) ArticleRepository {
+	return NewCachedArticleRepository(&fakeArticleRepository{store: store}, cache.NewLRU(100, 0), &cache.Counters{})
+}
+
+func pageIDs(t *testing.T, repo ArticleRepository, filter *model.ArticleFilter) []int64 {
+	t.Helper()
+
+	articles, err := repo.FindPage(filter, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int64{}
+	for _, article := range *articles {
+		ids = append(ids, article.ID)
+	}
+	return ids
+}
+
+func findArticle(t *testing.T, repo ArticleRepository, articleID int64) *model.ArticleResponse {
+	t.Helper()
+
+	article, err := repo.FindByID(articleID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return article
+}
+
+func TestCachedArticleRepositoryServesCachedReads(t *testing.T) {
+	store := newFakeStore()
+	repo := newCachedArticles(store)
+	findArticle(t, repo, 1)
+
+	store.articles[1] = model.ArticleResponse{ID: 1, Title: "Changed elsewhere", CategoryID: 1}
+	if got := findArticle(t, repo, 1).Title; got != "First" {
+		t.Errorf("title %q, want the cached First", got)
+	}
+}
+
+func TestCachedArticleRepositoryDoesNotCacheMissingRows(t *testing.T) {
+	store := newFakeStore()
+	repo := newCachedArticles(store)
+	if article := findArticle(t, repo, 4); article != nil {
+		t.Fatalf("found %+v before it existed", article)
+	}
+
+	store.articles[4] = model.ArticleResponse{ID: 4, Title: "Fourth", CategoryID: 2}
+	if article := findArticle(t, repo, 4); article == nil || article.Title != "Fourth" {
+		t.Errorf("got %+v, want the new article", article)
+	}
+}
+
+func TestCachedArticleRepositoryUpdateInvalidates(t *testing.T) {
+	store := newFakeStore()
+	repo := newCachedArticles(store)
+	findArticle(t, repo, 1)
+	findArticle(t, repo, 2)
+	pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{2}})
+
+	if err := repo.Update(1, &entity.Article{Title: "Renamed", CategoryID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findArticle(t, repo, 1); got.Title != "Renamed" || got.CategoryName != "Sports" {
+		t.Errorf("got %q in %q, want Renamed in Sports", got.Title, got.CategoryName)
+	}
+	if got := pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{2}}); len(got) != 2 {
+		t.Errorf("category 2 lists %v, want the updated article in it", got)
+	}
+
+	// Other articles keep their entries.
+	store.articles[2] = model.ArticleResponse{ID: 2, Title: "Changed elsewhere", CategoryID: 1}
+	if got := findArticle(t, repo, 2).Title; got != "Second" {
+		t.Errorf("title %q, want the cached Second", got)
+	}
+}
+
+func TestCachedArticleRepositoryMoveInvalidates(t *testing.T) {
+	store := newFakeStore()
+	repo := newCachedArticles(store)
+	findArticle(t, repo, 1)
+	findArticle(t, repo, 2)
+	pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{1}})
+	pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{2}})
+
+	if _, err := repo.MoveToCategory([]int64{1, 2}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, articleID := range []int64{1, 2} {
+		if got := findArticle(t, repo, articleID); got.CategoryID != 2 || got.CategorySlug != "sports" {
+			t.Errorf("article %d is in %d (%s), want 2 (sports)", articleID, got.CategoryID, got.CategorySlug)
+		}
+	}
+	if got := pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{1}}); len(got) != 0 {
+		t.Errorf("category 1 still lists %v", got)
+	}
+	if got := pageIDs(t, repo, &model.ArticleFilter{CategoryIDs: []int64{2}}); len(got) != 3 {
+		t.Errorf("category 2 lists %v, want 1, 2 and 3", got)
+	}
+}
@@ -4,217 +4,322 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/muhammadrijalkamal/backendtest/entity"
 	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const (
+	articleInsertColumns   = "title, slug, category_id, content, content_format, content_html, content_text, toc, excerpt, word_count, reading_time_minutes, status, published_at, featured_image_id, created_at"
+	articleInsertValues    = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, IF(? = 'published', COALESCE(?, NOW()), NULL), NULLIF(?, 0), COALESCE(?, NOW()))"
+	articleInsertBatchSize = 500
 )
 
 type ArticleRepositoryImpl struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Blobs storage.BlobStore
 }
 
-func NewArticleRepository(db *sql.DB) ArticleRepository {
+func NewArticleRepository(db *sql.DB, blobs storage.BlobStore) ArticleRepository {
 	return &ArticleRepositoryImpl{
-		DB: db,
+		DB:    db,
+		Blobs: blobs,
 	}
 }
 
-func (r *ArticleRepositoryImpl) Insert(request *entity.Article) error {
-	query := "INSERT INTO articles (title, slug, category_id, content) VALUES (?, ?, ?, ?)"
-	result, err1 := r.DB.ExecContext(context.Background(), query, request.Title, request.Slug, request.CategoryID, request.Content)
+func (r *ArticleRepositoryImpl) Insert(request *entity.Article) (int64, error) {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
-		return err1
+		return 0, err1
 	}
 
-	affected, err2 := result.RowsAffected()
-	if err2 != nil {
-		return err2
+	defer tx.Rollback()
+
+	if err2 := checkFeaturedImages(tx, *request); err2 != nil {
+		return 0, err2
+	}
+
+	query := "INSERT INTO articles (" + articleInsertColumns + ") VALUES " + articleInsertValues
+	result, err3 := tx.ExecContext(context.Background(), query, articleInsertArgs(request)...)
+	if err3 != nil {
+		return 0, duplicateEntry(err3, "an article with this slug already exists")
+	}
+
+	affected, err4 := result.RowsAffected()
+	if err4 != nil {
+		return 0, err4
 	}
 
 	if affected != 1 {
-		return errors.New("no article saved")
+		return 0, errors.New("no article saved")
 	}
 
-	return nil
+	id, err5 := result.LastInsertId()
+	if err5 != nil {
+		return 0, err5
+	}
+
+	if err6 := writeArticleEvents(tx, model.EventArticleCreated, []int64{id}); err6 != nil {
+		return 0, err6
+	}
+
+	return id, tx.Commit()
 }
 
-func (r *ArticleRepositoryImpl) FindAll() (*[]model.ArticleResponse, error) {
-	query := `SELECT a.id, a.title, a.slug, c.id AS category_id, c.category_name, c.category_slug, a.content, a.created_at, a.updated_at, a.deleted_at
-				FROM articles AS a INNER JOIN categories AS c on a.category_id = c.id WHERE a.deleted_at IS NULL`
-	rows, err1 := r.DB.QueryContext(context.Background(), query)
+// InsertMany writes the articles with multi-row INSERT statements inside a
+// single transaction and returns their IDs in the order they were given.
+func (r *ArticleRepositoryImpl) InsertMany(requests []entity.Article) ([]int64, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return nil, err1
 	}
 
-	defer rows.Close()
-	var articles []model.ArticleResponse
-	for rows.Next() {
-		var id, categoryID int64
-		var title, slug, categoryName, categorySlug, content string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&title,
-			&slug,
-			&categoryID,
-			&categoryName,
-			&categorySlug,
-			&content,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	defer tx.Rollback()
+
+	if err := checkFeaturedImages(tx, requests...); err != nil {
+		return nil, err
+	}
+
+	slugs := make([]interface{}, 0, len(requests))
+	for start := 0; start < len(requests); start += articleInsertBatchSize {
+		end := start + articleInsertBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
 
+		batch := requests[start:end]
+		values := strings.TrimSuffix(strings.Repeat(articleInsertValues+", ", len(batch)), ", ")
+		var args []interface{}
+		for i := range batch {
+			args = append(args, articleInsertArgs(&batch[i])...)
+			slugs = append(slugs, batch[i].Slug)
+		}
+
+		query := "INSERT INTO articles (" + articleInsertColumns + ") VALUES " + values
+		result, err2 := tx.ExecContext(context.Background(), query, args...)
 		if err2 != nil {
-			return nil, err2
+			return nil, duplicateEntry(err2, "an article with one of these slugs already exists")
+		}
+
+		affected, err3 := result.RowsAffected()
+		if err3 != nil {
+			return nil, err3
+		}
+
+		if affected != int64(len(batch)) {
+			return nil, errors.New("not every article saved")
 		}
+	}
 
-		article := model.ArticleResponse{
-			ID:           id,
-			Title:        title,
-			Slug:         slug,
-			CategoryID:   categoryID,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			Content:      content,
-			CreatedAt:    createdAt,
+	// Auto-increment values of a multi-row insert are not guaranteed to be
+	// consecutive, so the IDs are read back through the unique slugs.
+	idsBySlug := map[string]int64{}
+	for start := 0; start < len(slugs); start += articleInsertBatchSize {
+		end := start + articleInsertBatchSize
+		if end > len(slugs) {
+			end = len(slugs)
 		}
 
-		if updatedAt.Valid {
-			article.UpdatedAt = updatedAt.Time
+		query := "SELECT id, slug FROM articles WHERE slug IN (" + placeholders(end-start) + ")"
+		rows, err4 := tx.QueryContext(context.Background(), query, slugs[start:end]...)
+		if err4 != nil {
+			return nil, err4
 		}
 
-		if deletedAt.Valid {
-			article.DeletedAt = deletedAt.Time
+		for rows.Next() {
+			var id int64
+			var articleSlug string
+			if err5 := rows.Scan(&id, &articleSlug); err5 != nil {
+				rows.Close()
+				return nil, err5
+			}
+			idsBySlug[articleSlug] = id
 		}
 
-		articles = append(articles, article)
+		rows.Close()
+		if err6 := rows.Err(); err6 != nil {
+			return nil, err6
+		}
+	}
+
+	ids := make([]int64, len(requests))
+	for i, request := range requests {
+		ids[i] = idsBySlug[request.Slug]
+	}
+
+	if err7 := writeArticleEvents(tx, model.EventArticleCreated, ids); err7 != nil {
+		return nil, err7
+	}
+
+	return ids, tx.Commit()
+}
+
+func (r *ArticleRepositoryImpl) FindAll(selection *model.Selection) (*[]model.ArticleResponse, error) {
+	query := articleSelect(selection) + " WHERE a.deleted_at IS NULL"
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return nil, err1
 	}
 
-	return &articles, nil
+	defer rows.Close()
+	return scanArticles(rows, selection)
 }
 
-func (r *ArticleRepositoryImpl) FindAllByTitle(title string) (*[]model.ArticleResponse, error) {
+func (r *ArticleRepositoryImpl) FindAllByTitle(title string, selection *model.Selection) (*[]model.ArticleResponse, error) {
 	filter := strings.ToLower(title)
-	query := `SELECT a.id, a.title, a.slug, c.id AS category_id, c.category_name, c.category_slug, a.content, a.created_at, a.updated_at, a.deleted_at
-				FROM articles AS a INNER JOIN categories AS c on a.category_id = c.id WHERE a.title REGEXP ? AND a.deleted_at IS NULL`
+	query := articleSelect(selection) + " WHERE a.title REGEXP ? AND a.deleted_at IS NULL"
 	rows, err1 := r.DB.QueryContext(context.Background(), query, filter)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	defer rows.Close()
-	var articles []model.ArticleResponse
-	for rows.Next() {
-		var id, categoryID int64
-		var title, slug, categoryName, categorySlug, content string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&title,
-			&slug,
-			&categoryID,
-			&categoryName,
-			&categorySlug,
-			&content,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	return scanArticles(rows, selection)
+}
 
+// ForEach reads the same rows as FindAll, or FindAllByTitle for a non-empty
+// title, but hands them to fn one at a time while the cursor is open, so
+// large listings are never held in memory as a whole.
+func (r *ArticleRepositoryImpl) ForEach(title string, selection *model.Selection, fn func(article *model.ArticleResponse) error) error {
+	query := articleSelect(selection) + " WHERE a.deleted_at IS NULL"
+	var args []interface{}
+	if title != "" {
+		query += " AND a.title REGEXP ?"
+		args = append(args, strings.ToLower(title))
+	}
+
+	rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return err1
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		article, err2 := scanArticle(rows, selection)
 		if err2 != nil {
-			return nil, err2
+			return err2
 		}
 
-		article := model.ArticleResponse{
-			ID:           id,
-			Title:        title,
-			Slug:         slug,
-			CategoryID:   categoryID,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			Content:      content,
-			CreatedAt:    createdAt,
+		if err3 := fn(article); err3 != nil {
+			return err3
 		}
+	}
 
-		if updatedAt.Valid {
-			article.UpdatedAt = updatedAt.Time
-		}
+	return rows.Err()
+}
 
-		if deletedAt.Valid {
-			article.DeletedAt = deletedAt.Time
-		}
+// FindPage reads one page of the articles matching filter, ordered by ID so
+// that pages stay stable while articles are added.
+func (r *ArticleRepositoryImpl) FindPage(filter *model.ArticleFilter, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	where, args := articleFilterWhere(filter)
+	query := articleSelect(selection) + where + " ORDER BY a.id LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
 
-		articles = append(articles, article)
+	rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return nil, err1
 	}
 
-	return &articles, nil
+	defer rows.Close()
+	return scanArticles(rows, selection)
 }
 
-func (r *ArticleRepositoryImpl) FindAllSoftDeleted() (*[]model.ArticleResponse, error) {
-	query := `SELECT a.id, a.title, a.slug, c.id AS category_id, c.category_name, c.category_slug, a.content, a.created_at, a.updated_at, a.deleted_at
-				FROM articles AS a INNER JOIN categories AS c on a.category_id = c.id WHERE a.deleted_at IS NOT NULL`
-	rows, err1 := r.DB.QueryContext(context.Background(), query)
+// FindPageByCategories reads the page of filter separately for each category,
+// ignoring filter.CategoryIDs, in a single UNION ALL query. The rows are
+// grouped by category_id, which is selected even if selection leaves it out.
+func (r *ArticleRepositoryImpl) FindPageByCategories(categoryIDs []int64, filter *model.ArticleFilter, selection *model.Selection) (map[int64][]model.ArticleResponse, error) {
+	grouped := make(map[int64][]model.ArticleResponse, len(categoryIDs))
+	if len(categoryIDs) == 0 {
+		return grouped, nil
+	}
+
+	if selection != nil && len(selection.Fields) > 0 && !selection.HasField("category_id") {
+		withCategory := *selection
+		withCategory.Fields = append(append([]string{}, selection.Fields...), "category_id")
+		selection = &withCategory
+	}
+
+	where, filterArgs := articleFilterWhere(&model.ArticleFilter{Status: filter.Status, Title: filter.Title})
+	parts := make([]string, 0, len(categoryIDs))
+	var args []interface{}
+	for _, categoryID := range categoryIDs {
+		parts = append(parts, "("+articleSelect(selection)+where+" AND a.category_id = ? ORDER BY a.id LIMIT ? OFFSET ?)")
+		args = append(args, filterArgs...)
+		args = append(args, categoryID, filter.Limit, filter.Offset)
+	}
+
+	rows, err1 := r.DB.QueryContext(context.Background(), strings.Join(parts, " UNION ALL "), args...)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	defer rows.Close()
-	var articles []model.ArticleResponse
-	for rows.Next() {
-		var id, categoryID int64
-		var title, slug, categoryName, categorySlug, content string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&title,
-			&slug,
-			&categoryID,
-			&categoryName,
-			&categorySlug,
-			&content,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	articles, err2 := scanArticles(rows, selection)
+	if err2 != nil {
+		return nil, err2
+	}
 
-		if err2 != nil {
-			return nil, err2
-		}
+	for _, article := range *articles {
+		grouped[article.CategoryID] = append(grouped[article.CategoryID], article)
+	}
 
-		article := model.ArticleResponse{
-			ID:           id,
-			Title:        title,
-			Slug:         slug,
-			CategoryID:   categoryID,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			Content:      content,
-			CreatedAt:    createdAt,
-		}
+	return grouped, nil
+}
 
-		if updatedAt.Valid {
-			article.UpdatedAt = updatedAt.Time
-		}
+func (r *ArticleRepositoryImpl) Count(filter *model.ArticleFilter) (int64, error) {
+	where, args := articleFilterWhere(filter)
+	query := "SELECT COUNT(*)" + articleFrom + where
 
-		if deletedAt.Valid {
-			article.DeletedAt = deletedAt.Time
-		}
+	var count int64
+	err1 := r.DB.QueryRowContext(context.Background(), query, args...).Scan(&count)
+	return count, err1
+}
+
+func (r *ArticleRepositoryImpl) FindAllPublished(categoryID int64, limit int, selection *model.Selection) (*[]model.ArticleResponse, error) {
+	query := articleSelect(selection) + " WHERE a.deleted_at IS NULL AND a.status = 'published'"
+	var args []interface{}
+	if categoryID != 0 {
+		query += " AND a.category_id = ?"
+		args = append(args, categoryID)
+	}
+
+	query += " ORDER BY a.published_at DESC, a.id DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
 
-		articles = append(articles, article)
+	rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return nil, err1
 	}
 
-	return &articles, nil
+	defer rows.Close()
+	return scanArticles(rows, selection)
 }
 
-func (r *ArticleRepositoryImpl) FindByID(articleID int64) (*model.ArticleResponse, error) {
-	query := `SELECT a.id, a.title, a.slug, c.id AS category_id, c.category_name, c.category_slug, a.content, a.created_at, a.updated_at, a.deleted_at
-				FROM articles AS a INNER JOIN categories AS c on a.category_id = c.id WHERE a.id = ?`
+func (r *ArticleRepositoryImpl) FindAllSoftDeleted(selection *model.Selection) (*[]model.ArticleResponse, error) {
+	query := articleSelect(selection) + " WHERE a.deleted_at IS NOT NULL"
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanArticles(rows, selection)
+}
+
+func (r *ArticleRepositoryImpl) FindByID(articleID int64, selection *model.Selection) (*model.ArticleResponse, error) {
+	query := articleSelect(selection) + " WHERE a.id = ?"
 	rows, err1 := r.DB.QueryContext(context.Background(), query, articleID)
 	if err1 != nil {
 		return nil, err1
@@ -222,104 +327,461 @@ func (r *ArticleRepositoryImpl) FindByID(articleID int64) (*model.ArticleRespons
 
 	defer rows.Close()
 	if rows.Next() {
-		var id, categoryID int64
-		var title, slug, categoryName, categorySlug, content string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&title,
-			&slug,
-			&categoryID,
-			&categoryName,
-			&categorySlug,
-			&content,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+		return scanArticle(rows, selection)
+	}
 
-		if err2 != nil {
+	return nil, rows.Err()
+}
+
+func (r *ArticleRepositoryImpl) FindIDsByCategory(categoryID int64) ([]int64, error) {
+	query := "SELECT id FROM articles WHERE category_id = ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, categoryID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err2 := rows.Scan(&id); err2 != nil {
 			return nil, err2
 		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
 
-		article := model.ArticleResponse{
-			ID:           id,
-			Title:        title,
-			Slug:         slug,
-			CategoryID:   categoryID,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			Content:      content,
-			CreatedAt:    createdAt,
+// FindIDsBySlugs maps each of the slugs that exist, soft-deleted or not, to
+// the article ID.
+func (r *ArticleRepositoryImpl) FindIDsBySlugs(slugs []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	for start := 0; start < len(slugs); start += articleInsertBatchSize {
+		end := start + articleInsertBatchSize
+		if end > len(slugs) {
+			end = len(slugs)
 		}
 
-		if updatedAt.Valid {
-			article.UpdatedAt = updatedAt.Time
+		args := make([]interface{}, end-start)
+		for i, articleSlug := range slugs[start:end] {
+			args[i] = articleSlug
 		}
 
-		if deletedAt.Valid {
-			article.DeletedAt = deletedAt.Time
+		query := "SELECT id, slug FROM articles WHERE slug IN (" + placeholders(len(args)) + ")"
+		rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
+		if err1 != nil {
+			return nil, err1
 		}
 
-		return &article, nil
+		for rows.Next() {
+			var id int64
+			var articleSlug string
+			if err2 := rows.Scan(&id, &articleSlug); err2 != nil {
+				rows.Close()
+				return nil, err2
+			}
+			ids[articleSlug] = id
+		}
+
+		rows.Close()
+		if err3 := rows.Err(); err3 != nil {
+			return nil, err3
+		}
 	}
 
-	return nil, nil
+	return ids, nil
 }
 
+// Update keeps the stored status when request.Status is empty. MySQL assigns
+// from left to right, so published_at sees the status being written.
 func (r *ArticleRepositoryImpl) Update(articleID int64, request *entity.Article) error {
-	query := "UPDATE articles SET title = ?, slug = ? , category_id = ?, content = ? WHERE id = ?"
-	result, err1 := r.DB.ExecContext(context.Background(), query, request.Title, request.Slug, request.CategoryID, request.Content, articleID)
+	query := `UPDATE articles SET title = ?, slug = ? , category_id = ?, content = ?, content_format = ?, content_html = ?, content_text = ?, toc = ?,
+				excerpt = ?, word_count = ?, reading_time_minutes = ?, status = COALESCE(NULLIF(?, ''), status), published_at = IF(status = 'published', COALESCE(published_at, NOW()), NULL), featured_image_id = NULLIF(?, 0) WHERE id = ?`
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return err1
 	}
 
-	affected, err2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	if err := checkFeaturedImages(tx, *request); err != nil {
+		return err
+	}
+
+	result, err2 := tx.ExecContext(context.Background(), query, request.Title, request.Slug, request.CategoryID, request.Content,
+		request.ContentFormat, request.ContentHTML, request.ContentText, request.TOC, request.Excerpt, request.WordCount, request.ReadingTime, request.Status, request.FeaturedImageID, articleID)
 	if err2 != nil {
-		return err2
+		return duplicateEntry(err2, "an article with this slug already exists")
+	}
+
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return err3
 	}
 
 	if affected != 1 {
-		return errors.New("no article updated")
+		return util.NotFoundError("no article updated")
 	}
 
-	return nil
+	if err4 := writeArticleEvents(tx, model.EventArticleUpdated, []int64{articleID}); err4 != nil {
+		return err4
+	}
+
+	return tx.Commit()
 }
 
 func (r *ArticleRepositoryImpl) SoftDelete(articleID int64) error {
-	query := "UPDATE articles SET deleted_at = NOW() WHERE id = ?"
-	result, err1 := r.DB.ExecContext(context.Background(), query, articleID)
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return err1
 	}
 
-	affected, err2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	query := "UPDATE articles SET deleted_at = NOW() WHERE id = ?"
+	result, err2 := tx.ExecContext(context.Background(), query, articleID)
 	if err2 != nil {
 		return err2
 	}
 
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return err3
+	}
+
 	if affected != 1 {
-		return errors.New("no article deleted")
+		return util.NotFoundError("no article deleted")
 	}
 
-	return nil
+	if err4 := writeArticleEvents(tx, model.EventArticleSoftDeleted, []int64{articleID}); err4 != nil {
+		return err4
+	}
+
+	return tx.Commit()
 }
 
 func (r *ArticleRepositoryImpl) Delete(articleID int64) error {
-	query := "DELETE FROM articles WHERE id = ?"
-	result, err1 := r.DB.ExecContext(context.Background(), query, articleID)
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return err1
 	}
 
-	affected, err2 := result.RowsAffected()
-	if err2 != nil {
+	defer tx.Rollback()
+
+	if err2 := writeArticleEvents(tx, model.EventArticleDeleted, []int64{articleID}); err2 != nil {
 		return err2
 	}
 
+	query := "DELETE FROM comments WHERE article_id = ?"
+	if _, err3 := tx.ExecContext(context.Background(), query, articleID); err3 != nil {
+		return err3
+	}
+
+	blobKeys, err4 := deleteArticleMedia(tx, []interface{}{articleID})
+	if err4 != nil {
+		return err4
+	}
+
+	query = "DELETE FROM articles WHERE id = ?"
+	result, err5 := tx.ExecContext(context.Background(), query, articleID)
+	if err5 != nil {
+		return err5
+	}
+
+	affected, err6 := result.RowsAffected()
+	if err6 != nil {
+		return err6
+	}
+
 	if affected != 1 {
-		return errors.New("no article deleted")
+		return util.NotFoundError("no article deleted")
+	}
+
+	if err7 := tx.Commit(); err7 != nil {
+		return err7
+	}
+
+	r.removeBlobs(blobKeys)
+	return nil
+}
+
+func (r *ArticleRepositoryImpl) SoftDeleteMany(articleIDs []int64, atomic bool) ([]int64, error) {
+	return r.deleteMany(articleIDs, atomic, " AND deleted_at IS NULL", "UPDATE articles SET deleted_at = NOW()", model.EventArticleSoftDeleted)
+}
+
+func (r *ArticleRepositoryImpl) DeleteMany(articleIDs []int64, atomic bool) ([]int64, error) {
+	return r.deleteMany(articleIDs, atomic, "", "DELETE FROM articles", model.EventArticleDeleted)
+}
+
+// deleteMany locks the matching rows, applies the statement to the ones that
+// exist and returns their IDs. In atomic mode a single missing ID aborts the
+// whole operation.
+func (r *ArticleRepositoryImpl) deleteMany(articleIDs []int64, atomic bool, filter string, statement string, eventType string) ([]int64, error) {
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer tx.Rollback()
+
+	unique := map[int64]bool{}
+	args := make([]interface{}, 0, len(articleIDs))
+	for _, articleID := range articleIDs {
+		if !unique[articleID] {
+			unique[articleID] = true
+			args = append(args, articleID)
+		}
+	}
+
+	query := "SELECT id FROM articles WHERE id IN (" + placeholders(len(args)) + ")" + filter + " FOR UPDATE"
+	rows, err2 := tx.QueryContext(context.Background(), query, args...)
+	if err2 != nil {
+		return nil, err2
+	}
+
+	var found []int64
+	var foundArgs []interface{}
+	for rows.Next() {
+		var id int64
+		if err3 := rows.Scan(&id); err3 != nil {
+			rows.Close()
+			return nil, err3
+		}
+		found = append(found, id)
+		foundArgs = append(foundArgs, id)
+	}
+
+	rows.Close()
+	if err4 := rows.Err(); err4 != nil {
+		return nil, err4
+	}
+
+	if atomic && len(found) != len(args) {
+		return nil, util.NotFoundError("some articles were not found")
+	}
+
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	var blobKeys []string
+
+	// Hard deletes have to snapshot the rows before they are gone.
+	if eventType == model.EventArticleDeleted {
+		if err5 := writeArticleEvents(tx, eventType, found); err5 != nil {
+			return nil, err5
+		}
+
+		query = "DELETE FROM comments WHERE article_id IN (" + placeholders(len(found)) + ")"
+		if _, err5 := tx.ExecContext(context.Background(), query, foundArgs...); err5 != nil {
+			return nil, err5
+		}
+
+		keys, err5 := deleteArticleMedia(tx, foundArgs)
+		if err5 != nil {
+			return nil, err5
+		}
+		blobKeys = keys
+	}
+
+	query = statement + " WHERE id IN (" + placeholders(len(found)) + ")"
+	_, err6 := tx.ExecContext(context.Background(), query, foundArgs...)
+	if err6 != nil {
+		return nil, err6
+	}
+
+	if eventType != model.EventArticleDeleted {
+		if err7 := writeArticleEvents(tx, eventType, found); err7 != nil {
+			return nil, err7
+		}
+	}
+
+	if err8 := tx.Commit(); err8 != nil {
+		return nil, err8
+	}
+
+	r.removeBlobs(blobKeys)
+	return found, nil
+}
+
+// removeBlobs deletes the files of garbage-collected media after commit. A
+// blob that a concurrent upload has registered again in the meantime is kept;
+// the key lock keeps that upload from writing its row between the check and
+// the delete.
+func (r *ArticleRepositoryImpl) removeBlobs(keys []string) {
+	for _, key := range keys {
+		r.removeBlob(key)
+	}
+}
+
+func (r *ArticleRepositoryImpl) removeBlob(key string) {
+	unlock := storage.LockKey(key)
+	defer unlock()
+
+	var uploads int64
+	query := "SELECT COUNT(*) FROM media WHERE sha256 = ?"
+	if err := r.DB.QueryRowContext(context.Background(), query, key).Scan(&uploads); err != nil || uploads > 0 {
+		return
+	}
+
+	if err := r.Blobs.Delete(key); err != nil {
+		log.Printf("media: removing blob %s: %v", key, err)
+	}
+}
+
+func (r *ArticleRepositoryImpl) MoveToCategory(articleIDs []int64, categoryID int64) (int64, error) {
+	articleIDs = uniqueIDs(articleIDs)
+	if len(articleIDs) == 0 {
+		return 0, nil
+	}
+
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return 0, err1
+	}
+
+	defer tx.Rollback()
+
+	var liveCategories int64
+	query := "SELECT COUNT(*) FROM categories WHERE id = ? AND deleted_at IS NULL"
+	err2 := tx.QueryRowContext(context.Background(), query, categoryID).Scan(&liveCategories)
+	if err2 != nil {
+		return 0, err2
+	}
+
+	if liveCategories != 1 {
+		return 0, util.InvalidError("category not found")
+	}
+
+	args := []interface{}{categoryID}
+	for _, articleID := range articleIDs {
+		args = append(args, articleID)
+	}
+
+	query = "UPDATE articles SET category_id = ? WHERE id IN (" + placeholders(len(articleIDs)) + ")"
+	result, err3 := tx.ExecContext(context.Background(), query, args...)
+	if err3 != nil {
+		return 0, err3
+	}
+
+	affected, err4 := result.RowsAffected()
+	if err4 != nil {
+		return 0, err4
+	}
+
+	if err5 := writeArticleEvents(tx, model.EventArticleMoved, articleIDs); err5 != nil {
+		return 0, err5
+	}
+
+	return affected, tx.Commit()
+}
+
+func articleFilterWhere(filter *model.ArticleFilter) (string, []interface{}) {
+	where := " WHERE a.deleted_at IS NULL"
+	var args []interface{}
+	if len(filter.CategoryIDs) > 0 {
+		where += " AND a.category_id IN (" + placeholders(len(filter.CategoryIDs)) + ")"
+		for _, categoryID := range filter.CategoryIDs {
+			args = append(args, categoryID)
+		}
+	}
+
+	if filter.Status != "" {
+		where += " AND a.status = ?"
+		args = append(args, filter.Status)
+	}
+
+	if filter.Title != "" {
+		where += " AND a.title REGEXP ?"
+		args = append(args, strings.ToLower(filter.Title))
+	}
+
+	return where, args
+}
+
+// uniqueIDs drops repeated IDs and keeps the order of their first use.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func articleInsertArgs(request *entity.Article) []interface{} {
+	return []interface{}{
+		request.Title,
+		request.Slug,
+		request.CategoryID,
+		request.Content,
+		request.ContentFormat,
+		request.ContentHTML,
+		request.ContentText,
+		request.TOC,
+		request.Excerpt,
+		request.WordCount,
+		request.ReadingTime,
+		request.Status,
+		request.Status,
+		nullTime(request.PublishedAt),
+		request.FeaturedImageID,
+		nullTime(request.CreatedAt),
+	}
+}
+
+// nullTime lets a zero time fall back to the column's default.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// duplicateEntry reports MySQL's duplicate key error (1062) as a conflict, so
+// that a name or slug already in use is not answered as a server error.
+func duplicateEntry(err error, message string) error {
+	if strings.Contains(err.Error(), "Error 1062") {
+		return util.ConflictError(message)
+	}
+	return err
+}
+
+// checkFeaturedImages rejects featured images that are not uploaded images.
+func checkFeaturedImages(tx *sql.Tx, requests ...entity.Article) error {
+	unique := map[int64]bool{}
+	var args []interface{}
+	for _, request := range requests {
+		if request.FeaturedImageID != 0 && !unique[request.FeaturedImageID] {
+			unique[request.FeaturedImageID] = true
+			args = append(args, request.FeaturedImageID)
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	var images int
+	query := "SELECT COUNT(*) FROM media WHERE id IN (" + placeholders(len(args)) + ") AND content_type LIKE 'image/%'"
+	if err := tx.QueryRowContext(context.Background(), query, args...).Scan(&images); err != nil {
+		return err
+	}
+
+	if images != len(args) {
+		return util.InvalidError("featured_image_id must reference an uploaded image")
 	}
 
 	return nil
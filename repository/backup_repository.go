@@ -0,0 +1,17 @@
+package repository
+
+type BackupRepository interface {
+	Export(visit func(table string, record interface{}) error) error
+
+	BeginRestore() (RestoreTx, error)
+}
+
+// RestoreTx writes the records of an export inside one transaction, so a
+// failed restore leaves the database empty.
+type RestoreTx interface {
+	Insert(table string, record interface{}) error
+
+	Commit() error
+
+	Rollback() error
+}
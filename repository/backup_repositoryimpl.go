@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const restoreBatchSize = 200
+
+type backupTable struct {
+	columns []string
+	order   string
+	scan    func(rows *sql.Rows) (interface{}, error)
+	values  func(record interface{}) []interface{}
+}
+
+var backupTables = map[string]backupTable{
+	model.ExportTableCategories: {
+		columns: []string{"id", "category_name", "category_slug", "created_at", "updated_at", "deleted_at"},
+		order:   "id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportCategory
+			var updatedAt, deletedAt sql.NullTime
+			err := rows.Scan(&record.ID, &record.CategoryName, &record.CategorySlug, &record.CreatedAt, &updatedAt, &deletedAt)
+			record.UpdatedAt, record.DeletedAt = timePointer(updatedAt), timePointer(deletedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportCategory)
+			return []interface{}{r.ID, r.CategoryName, r.CategorySlug, r.CreatedAt, r.UpdatedAt, r.DeletedAt}
+		},
+	},
+	model.ExportTableCategoryRedirects: {
+		columns: []string{"old_slug", "category_id", "created_at"},
+		order:   "old_slug",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportCategoryRedirect
+			err := rows.Scan(&record.OldSlug, &record.CategoryID, &record.CreatedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportCategoryRedirect)
+			return []interface{}{r.OldSlug, r.CategoryID, r.CreatedAt}
+		},
+	},
+	model.ExportTableMedia: {
+		columns: []string{"id", "sha256", "content_type", "size", "filename", "width", "height", "created_at"},
+		order:   "id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportMedia
+			err := rows.Scan(&record.ID, &record.SHA256, &record.ContentType, &record.Size, &record.Filename, &record.Width, &record.Height, &record.CreatedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportMedia)
+			return []interface{}{r.ID, r.SHA256, r.ContentType, r.Size, r.Filename, r.Width, r.Height, r.CreatedAt}
+		},
+	},
+	model.ExportTableArticles: {
+		columns: []string{"id", "title", "slug", "category_id", "content", "content_format", "content_html", "content_text", "toc", "excerpt",
+			"word_count", "reading_time_minutes", "status", "featured_image_id", "published_at", "created_at", "updated_at", "deleted_at"},
+		order: "id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportArticle
+			var featuredImageID sql.NullInt64
+			var publishedAt, updatedAt, deletedAt sql.NullTime
+			err := rows.Scan(&record.ID, &record.Title, &record.Slug, &record.CategoryID, &record.Content, &record.ContentFormat,
+				&record.ContentHTML, &record.ContentText, &record.TOC, &record.Excerpt, &record.WordCount, &record.ReadingTime,
+				&record.Status, &featuredImageID, &publishedAt, &record.CreatedAt, &updatedAt, &deletedAt)
+			if featuredImageID.Valid {
+				record.FeaturedImageID = &featuredImageID.Int64
+			}
+			record.PublishedAt, record.UpdatedAt, record.DeletedAt = timePointer(publishedAt), timePointer(updatedAt), timePointer(deletedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportArticle)
+			return []interface{}{r.ID, r.Title, r.Slug, r.CategoryID, r.Content, r.ContentFormat, r.ContentHTML, r.ContentText, r.TOC, r.Excerpt,
+				r.WordCount, r.ReadingTime, r.Status, r.FeaturedImageID, r.PublishedAt, r.CreatedAt, r.UpdatedAt, r.DeletedAt}
+		},
+	},
+	model.ExportTableAttachments: {
+		columns: []string{"article_id", "media_id", "created_at"},
+		order:   "article_id, media_id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportAttachment
+			err := rows.Scan(&record.ArticleID, &record.MediaID, &record.CreatedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportAttachment)
+			return []interface{}{r.ArticleID, r.MediaID, r.CreatedAt}
+		},
+	},
+	model.ExportTableComments: {
+		columns: []string{"id", "article_id", "parent_id", "author_name", "author_email", "body", "status", "created_at", "updated_at", "deleted_at"},
+		order:   "id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportComment
+			var parentID sql.NullInt64
+			var updatedAt, deletedAt sql.NullTime
+			err := rows.Scan(&record.ID, &record.ArticleID, &parentID, &record.AuthorName, &record.AuthorEmail, &record.Body, &record.Status,
+				&record.CreatedAt, &updatedAt, &deletedAt)
+			if parentID.Valid {
+				record.ParentID = &parentID.Int64
+			}
+			record.UpdatedAt, record.DeletedAt = timePointer(updatedAt), timePointer(deletedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportComment)
+			return []interface{}{r.ID, r.ArticleID, r.ParentID, r.AuthorName, r.AuthorEmail, r.Body, r.Status, r.CreatedAt, r.UpdatedAt, r.DeletedAt}
+		},
+	},
+	model.ExportTableWebhooks: {
+		columns: []string{"id", "url", "secret", "events", "active", "created_at", "updated_at"},
+		order:   "id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportWebhook
+			var updatedAt sql.NullTime
+			err := rows.Scan(&record.ID, &record.URL, &record.Secret, &record.Events, &record.Active, &record.CreatedAt, &updatedAt)
+			record.UpdatedAt = timePointer(updatedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportWebhook)
+			return []interface{}{r.ID, r.URL, r.Secret, r.Events, r.Active, r.CreatedAt, r.UpdatedAt}
+		},
+	},
+	model.ExportTableImportMappings: {
+		columns: []string{"source", "kind", "external_id", "target_id", "created_at"},
+		order:   "source, kind, external_id",
+		scan: func(rows *sql.Rows) (interface{}, error) {
+			var record model.ExportImportMapping
+			err := rows.Scan(&record.Source, &record.Kind, &record.ExternalID, &record.TargetID, &record.CreatedAt)
+			return &record, err
+		},
+		values: func(record interface{}) []interface{} {
+			r := record.(*model.ExportImportMapping)
+			return []interface{}{r.Source, r.Kind, r.ExternalID, r.TargetID, r.CreatedAt}
+		},
+	},
+}
+
+type BackupRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewBackupRepository(db *sql.DB) BackupRepository {
+	return &BackupRepositoryImpl{
+		DB: db,
+	}
+}
+
+// Export reads all tables in a single repeatable-read transaction, which
+// InnoDB serves from one snapshot, so rows written meanwhile are either
+// exported everywhere or nowhere.
+func (r *BackupRepositoryImpl) Export(visit func(table string, record interface{}) error) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	for _, name := range model.ExportTables {
+		table := backupTables[name]
+		query := "SELECT " + strings.Join(table.columns, ", ") + " FROM " + name + " ORDER BY " + table.order
+		rows, err2 := tx.QueryContext(context.Background(), query)
+		if err2 != nil {
+			return err2
+		}
+
+		for rows.Next() {
+			record, err3 := table.scan(rows)
+			if err3 == nil {
+				err3 = visit(name, record)
+			}
+			if err3 != nil {
+				rows.Close()
+				return err3
+			}
+		}
+
+		rows.Close()
+		if err4 := rows.Err(); err4 != nil {
+			return err4
+		}
+	}
+
+	return tx.Commit()
+}
+
+// BeginRestore refuses to restore into a database that already has
+// categories, articles, media, comments or webhooks, since the export's IDs
+// would collide.
+func (r *BackupRepositoryImpl) BeginRestore() (RestoreTx, error) {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	for _, name := range []string{model.ExportTableCategories, model.ExportTableArticles, model.ExportTableMedia, model.ExportTableComments, model.ExportTableWebhooks} {
+		var exists bool
+		if err2 := tx.QueryRowContext(context.Background(), "SELECT EXISTS (SELECT 1 FROM "+name+")").Scan(&exists); err2 != nil {
+			tx.Rollback()
+			return nil, err2
+		}
+
+		if exists {
+			tx.Rollback()
+			return nil, fmt.Errorf("the database is not empty: table %s has rows", name)
+		}
+	}
+
+	return &restoreTx{tx: tx}, nil
+}
+
+type restoreTx struct {
+	tx      *sql.Tx
+	table   string
+	pending []interface{}
+}
+
+func (r *restoreTx) Insert(table string, record interface{}) error {
+	if _, ok := backupTables[table]; !ok {
+		return errors.New("unknown table " + table)
+	}
+
+	if table != r.table || len(r.pending) == restoreBatchSize {
+		if err := r.flush(); err != nil {
+			return err
+		}
+		r.table = table
+	}
+
+	r.pending = append(r.pending, record)
+	return nil
+}
+
+func (r *restoreTx) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	table := backupTables[r.table]
+	row := "(" + placeholders(len(table.columns)) + ")"
+	query := "INSERT INTO " + r.table + " (" + strings.Join(table.columns, ", ") + ") VALUES " +
+		strings.TrimSuffix(strings.Repeat(row+", ", len(r.pending)), ", ")
+
+	args := make([]interface{}, 0, len(r.pending)*len(table.columns))
+	for _, record := range r.pending {
+		args = append(args, table.values(record)...)
+	}
+
+	r.pending = r.pending[:0]
+	_, err := r.tx.ExecContext(context.Background(), query, args...)
+	return err
+}
+
+func (r *restoreTx) Commit() error {
+	if err := r.flush(); err != nil {
+		return err
+	}
+	return r.tx.Commit()
+}
+
+func (r *restoreTx) Rollback() error {
+	return r.tx.Rollback()
+}
+
+func timePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
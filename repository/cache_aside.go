@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const (
+	articleListGroup  = "articles"
+	categoryListGroup = "categories"
+)
+
+// cacheAside keys every cached value by a generation of the group it belongs
+// to. Invalidating a group drops its generation, which makes every entry of
+// the group unreachable at once and also discards values that a concurrent
+// reader loaded before the write but stores after it.
+type cacheAside struct {
+	cache    cache.Cache
+	counters *cache.Counters
+}
+
+func (c *cacheAside) key(group string, parts ...string) string {
+	return group + ":" + c.generation(group) + ":" + strings.Join(parts, ":")
+}
+
+func (c *cacheAside) generation(group string) string {
+	key := "gen:" + group
+	if value, ok := c.cache.Get(key); ok {
+		return string(value)
+	}
+
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	c.cache.Set(key, []byte(generation))
+	return generation
+}
+
+func (c *cacheAside) invalidate(groups ...string) {
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keys = append(keys, "gen:"+group)
+	}
+
+	c.cache.Delete(keys...)
+	c.counters.Invalidate()
+}
+
+func (c *cacheAside) invalidateArticles(articleIDs []int64) {
+	groups := []string{articleListGroup}
+	for _, articleID := range articleIDs {
+		groups = append(groups, articleGroup(articleID))
+	}
+	c.invalidate(groups...)
+}
+
+func (c *cacheAside) get(key string, dest interface{}) bool {
+	if raw, ok := c.cache.Get(key); ok && json.Unmarshal(raw, dest) == nil {
+		c.counters.Hit()
+		return true
+	}
+
+	c.counters.Miss()
+	return false
+}
+
+func (c *cacheAside) set(key string, value interface{}) {
+	if raw, err := json.Marshal(value); err == nil {
+		c.cache.Set(key, raw)
+	}
+}
+
+func articleGroup(articleID int64) string {
+	return "article:" + strconv.FormatInt(articleID, 10)
+}
+
+func categoryGroup(categoryID int64) string {
+	return "category:" + strconv.FormatInt(categoryID, 10)
+}
+
+func filterKey(filter *model.ArticleFilter) string {
+	return idsKey(filter.CategoryIDs) + "|" + filter.Status + "|" + strconv.Quote(filter.Title) + "|" + strconv.Itoa(filter.Limit) + "|" + strconv.Itoa(filter.Offset)
+}
+
+func idsKey(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func selectionKey(selection *model.Selection) string {
+	if selection == nil {
+		return "*"
+	}
+	return strings.Join(selection.Fields, ",") + "|" + strings.Join(selection.Expand, ",")
+}
@@ -6,17 +6,31 @@ import (
 )
 
 type CategoryRepository interface {
-	Insert(request *entity.Category) error
+	Insert(request *entity.Category) (int64, error)
 
 	FindAll() (*[]model.CategoryResponse, error)
 
+	FindAllWithStats() (*[]model.CategoryResponse, error)
+
+	ForEach(withStats bool, fn func(category *model.CategoryResponse) error) error
+
 	FindAllSoftDeleted() (*[]model.CategoryResponse, error)
 
 	FindByID(categoryID int64) (*model.CategoryResponse, error)
 
+	FindByIDWithStats(categoryID int64) (*model.CategoryResponse, error)
+
+	FindAllByIDs(categoryIDs []int64, withStats bool) (*[]model.CategoryResponse, error)
+
+	FindBySlug(categorySlug string) (*model.CategoryResponse, error)
+
+	FindRedirect(categorySlug string) (int64, error)
+
 	Update(categoryID int64, request *entity.Category) error
 
 	SoftDelete(categoryID int64) error
 
 	Delete(categoryID int64) error
+
+	Merge(sourceID int64, targetID int64) error
 }
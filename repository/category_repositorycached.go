@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// CachedCategoryRepository caches category reads. Counters depend on live
+// article data, so the *WithStats queries always go to the database.
+type CachedCategoryRepository struct {
+	CategoryRepository
+	cacheAside
+	articleRepository ArticleRepository
+}
+
+func NewCachedCategoryRepository(repo CategoryRepository, articleRepo ArticleRepository, c cache.Cache, counters *cache.Counters) CategoryRepository {
+	return &CachedCategoryRepository{
+		CategoryRepository: repo,
+		cacheAside:         cacheAside{cache: c, counters: counters},
+		articleRepository:  articleRepo,
+	}
+}
+
+func (r *CachedCategoryRepository) Insert(request *entity.Category) (int64, error) {
+	id, err := r.CategoryRepository.Insert(request)
+	r.invalidate(categoryListGroup, categoryGroup(id))
+	return id, err
+}
+
+func (r *CachedCategoryRepository) FindAll() (*[]model.CategoryResponse, error) {
+	key := r.key(categoryListGroup, "all")
+	var categories *[]model.CategoryResponse
+	if r.get(key, &categories) {
+		return categories, nil
+	}
+
+	categories, err := r.CategoryRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, categories)
+	return categories, nil
+}
+
+func (r *CachedCategoryRepository) FindAllSoftDeleted() (*[]model.CategoryResponse, error) {
+	key := r.key(categoryListGroup, "deleted")
+	var categories *[]model.CategoryResponse
+	if r.get(key, &categories) {
+		return categories, nil
+	}
+
+	categories, err := r.CategoryRepository.FindAllSoftDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, categories)
+	return categories, nil
+}
+
+func (r *CachedCategoryRepository) FindByID(categoryID int64) (*model.CategoryResponse, error) {
+	key := r.key(categoryGroup(categoryID))
+	var category *model.CategoryResponse
+	if r.get(key, &category) {
+		return category, nil
+	}
+
+	category, err := r.CategoryRepository.FindByID(categoryID)
+	if err != nil || category == nil {
+		return category, err
+	}
+
+	r.set(key, category)
+	return category, nil
+}
+
+// FindAllByIDs serves what it can from the entries FindByID caches and reads
+// only the rest, in one query.
+func (r *CachedCategoryRepository) FindAllByIDs(categoryIDs []int64, withStats bool) (*[]model.CategoryResponse, error) {
+	if withStats {
+		return r.CategoryRepository.FindAllByIDs(categoryIDs, withStats)
+	}
+
+	categories := make([]model.CategoryResponse, 0, len(categoryIDs))
+	var missing []int64
+	for _, categoryID := range categoryIDs {
+		var category *model.CategoryResponse
+		if r.get(r.key(categoryGroup(categoryID)), &category) && category != nil {
+			categories = append(categories, *category)
+		} else {
+			missing = append(missing, categoryID)
+		}
+	}
+
+	if len(missing) == 0 {
+		return &categories, nil
+	}
+
+	found, err := r.CategoryRepository.FindAllByIDs(missing, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *found {
+		category := &(*found)[i]
+		r.set(r.key(categoryGroup(category.ID)), category)
+		categories = append(categories, *category)
+	}
+
+	return &categories, nil
+}
+
+func (r *CachedCategoryRepository) FindBySlug(categorySlug string) (*model.CategoryResponse, error) {
+	key := r.key(categoryListGroup, "slug", categorySlug)
+	var category *model.CategoryResponse
+	if r.get(key, &category) {
+		return category, nil
+	}
+
+	category, err := r.CategoryRepository.FindBySlug(categorySlug)
+	if err != nil || category == nil {
+		return category, err
+	}
+
+	r.set(key, category)
+	return category, nil
+}
+
+func (r *CachedCategoryRepository) Update(categoryID int64, request *entity.Category) error {
+	err := r.CategoryRepository.Update(categoryID, request)
+	r.invalidateCategories(categoryID)
+	return err
+}
+
+func (r *CachedCategoryRepository) SoftDelete(categoryID int64) error {
+	err := r.CategoryRepository.SoftDelete(categoryID)
+	r.invalidateCategories(categoryID)
+	return err
+}
+
+func (r *CachedCategoryRepository) Delete(categoryID int64) error {
+	err := r.CategoryRepository.Delete(categoryID)
+	r.invalidateCategories(categoryID)
+	return err
+}
+
+func (r *CachedCategoryRepository) Merge(sourceID int64, targetID int64) error {
+	// The source's articles have to be looked up before they are moved.
+	articleIDs, err := r.articleRepository.FindIDsByCategory(sourceID)
+	if err != nil {
+		return err
+	}
+
+	err = r.CategoryRepository.Merge(sourceID, targetID)
+	r.invalidate(categoryGroup(sourceID), categoryGroup(targetID), categoryListGroup)
+	r.invalidateArticles(articleIDs)
+	return err
+}
+
+// invalidateCategories drops the category along with every cached article
+// that embeds its name or slug.
+func (r *CachedCategoryRepository) invalidateCategories(categoryID int64) {
+	r.invalidate(categoryGroup(categoryID), categoryListGroup)
+
+	articleIDs, err := r.articleRepository.FindIDsByCategory(categoryID)
+	if err != nil {
+		r.invalidate(articleListGroup)
+		return
+	}
+
+	r.invalidateArticles(articleIDs)
+}
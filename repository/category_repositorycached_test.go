@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type fakeCategoryRepository struct {
+	CategoryRepository
+	store *fakeStore
+}
+
+func (r *fakeCategoryRepository) FindAll() (*[]model.CategoryResponse, error) {
+	categories := []model.CategoryResponse{}
+	for _, category := range r.store.categories {
+		if category.DeletedAt.IsZero() {
+			categories = append(categories, category)
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return &categories, nil
+}
+
+func (r *fakeCategoryRepository) FindByID(categoryID int64) (*model.CategoryResponse, error) {
+	category, ok := r.store.categories[categoryID]
+	if !ok || !category.DeletedAt.IsZero() {
+		return nil, nil
+	}
+	return &category, nil
+}
+
+func (r *fakeCategoryRepository) FindBySlug(categorySlug string) (*model.CategoryResponse, error) {
+	for _, category := range r.store.categories {
+		if category.CategorySlug == categorySlug && category.DeletedAt.IsZero() {
+			return &category, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCategoryRepository) Update(categoryID int64, request *entity.Category) error {
+	category := r.store.categories[categoryID]
+	category.CategoryName, category.CategorySlug = request.CategoryName, request.CategorySlug
+	r.store.categories[categoryID] = category
+	return nil
+}
+
+func (r *fakeCategoryRepository) Merge(sourceID int64, targetID int64) error {
+	for articleID, article := range r.store.articles {
+		if article.CategoryID == sourceID {
+			article.CategoryID = targetID
+			r.store.articles[articleID] = article
+		}
+	}
+
+	source := r.store.categories[sourceID]
+	source.DeletedAt = time.Now()
+	r.store.categories[sourceID] = source
+	return nil
+}
+
+// newCachedRepositories wires both cached repositories to one cache, the way
+// main does, with the category side reading article IDs uncached.
+func newCachedRepositories(store *fakeStore) (ArticleRepository, CategoryRepository) {
+	lru := cache.NewLRU(100, 0)
+	counters := &cache.Counters{}
+	articles := &fakeArticleRepository{store: store}
+	return NewCachedArticleRepository(articles, lru, counters),
+		NewCachedCategoryRepository(&fakeCategoryRepository{store: store}, articles, lru, counters)
+}
+
+func categoryNames(t *testing.T, repo CategoryRepository) []string {
+	t.Helper()
+
+	categories, err := repo.FindAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, category := range *categories {
+		names = append(names, category.CategoryName)
+	}
+	return names
+}
+
+func TestCachedCategoryRepositoryRenameInvalidatesArticles(t *testing.T) {
+	store := newFakeStore()
+	articles, categories := newCachedRepositories(store)
+	findArticle(t, articles, 1)
+	findArticle(t, articles, 3)
+	categories.FindByID(1)
+	categories.FindBySlug("news")
+	categoryNames(t, categories)
+
+	if err := categories.Update(1, &entity.Category{CategoryName: "World", CategorySlug: "world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both articles of the category embed its name and slug.
+	for _, articleID := range []int64{1, 2} {
+		if got := findArticle(t, articles, articleID); got.CategoryName != "World" || got.CategorySlug != "world" {
+			t.Errorf("article %d embeds %q (%s), want World (world)", articleID, got.CategoryName, got.CategorySlug)
+		}
+	}
+	if category, _ := categories.FindByID(1); category == nil || category.CategoryName != "World" {
+		t.Errorf("category 1 is %+v, want World", category)
+	}
+	if category, _ := categories.FindBySlug("news"); category != nil {
+		t.Errorf("the old slug still finds %+v", category)
+	}
+	if got := categoryNames(t, categories); len(got) != 2 || got[0] != "World" {
+		t.Errorf("categories %v, want World first", got)
+	}
+
+	// Articles of other categories keep their entries.
+	store.articles[3] = model.ArticleResponse{ID: 3, Title: "Changed elsewhere", CategoryID: 2}
+	if got := findArticle(t, articles, 3).Title; got != "Third" {
+		t.Errorf("title %q, want the cached Third", got)
+	}
+}
+
+func TestCachedCategoryRepositoryMergeInvalidates(t *testing.T) {
+	store := newFakeStore()
+	articles, categories := newCachedRepositories(store)
+	findArticle(t, articles, 1)
+	findArticle(t, articles, 2)
+	pageIDs(t, articles, &model.ArticleFilter{CategoryIDs: []int64{2}})
+	categories.FindByID(1)
+	categories.FindBySlug("news")
+	categoryNames(t, categories)
+
+	if err := categories.Merge(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, articleID := range []int64{1, 2} {
+		if got := findArticle(t, articles, articleID); got.CategoryID != 2 || got.CategoryName != "Sports" {
+			t.Errorf("article %d is in %d (%s), want 2 (Sports)", articleID, got.CategoryID, got.CategoryName)
+		}
+	}
+	if got := pageIDs(t, articles, &model.ArticleFilter{CategoryIDs: []int64{2}}); len(got) != 3 {
+		t.Errorf("category 2 lists %v, want 1, 2 and 3", got)
+	}
+	if category, _ := categories.FindByID(1); category != nil {
+		t.Errorf("the merged category is still found: %+v", category)
+	}
+	if category, _ := categories.FindBySlug("news"); category != nil {
+		t.Errorf("the merged slug still finds %+v", category)
+	}
+	if got := categoryNames(t, categories); len(got) != 1 || got[0] != "Sports" {
+		t.Errorf("categories %v, want only Sports", got)
+	}
+}
@@ -8,8 +8,24 @@ import (
 
 	"github.com/muhammadrijalkamal/backendtest/entity"
 	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/util"
 )
 
+const categorySelectQuery = "SELECT id, category_name, category_slug, created_at, updated_at, deleted_at FROM categories"
+
+// categoryStatsSelectQuery aggregates the article counters in a single grouped
+// subquery so listing every category with its stats stays one round-trip.
+const categoryStatsSelectQuery = `SELECT c.id, c.category_name, c.category_slug, c.created_at, c.updated_at, c.deleted_at,
+				COALESCE(s.live_articles, 0), COALESCE(s.draft_articles, 0), COALESCE(s.deleted_articles, 0), s.last_published_at
+				FROM categories AS c LEFT JOIN (
+					SELECT category_id,
+						SUM(deleted_at IS NULL AND status = 'published') AS live_articles,
+						SUM(deleted_at IS NULL AND status = 'draft') AS draft_articles,
+						SUM(deleted_at IS NOT NULL) AS deleted_articles,
+						MAX(IF(deleted_at IS NULL AND status = 'published', published_at, NULL)) AS last_published_at
+					FROM articles GROUP BY category_id
+				) AS s ON s.category_id = c.id`
+
 type CategoryRepositoryImpl struct {
 	DB *sql.DB
 }
@@ -20,221 +36,437 @@ func NewCategoryRepository(db *sql.DB) CategoryRepository {
 	}
 }
 
-func (r *CategoryRepositoryImpl) Insert(request *entity.Category) error {
-	query := "INSERT INTO categories (category_name, category_slug) VALUES (?, ?)"
-	result, err1 := r.DB.ExecContext(context.Background(), query, request.CategoryName, request.CategorySlug)
+func (r *CategoryRepositoryImpl) Insert(request *entity.Category) (int64, error) {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
-		return err1
+		return 0, err1
 	}
 
-	affected, err2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	query := "INSERT INTO categories (category_name, category_slug) VALUES (?, ?)"
+	result, err2 := tx.ExecContext(context.Background(), query, request.CategoryName, request.CategorySlug)
 	if err2 != nil {
-		return err2
+		return 0, duplicateEntry(err2, "a category with this name already exists")
+	}
+
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return 0, err3
 	}
 
 	if affected != 1 {
-		return errors.New("no category saved")
+		return 0, errors.New("no category saved")
+	}
+
+	id, err4 := result.LastInsertId()
+	if err4 != nil {
+		return 0, err4
+	}
+
+	if err5 := writeCategoryEvents(tx, model.EventCategoryCreated, id); err5 != nil {
+		return 0, err5
 	}
 
-	return nil
+	return id, tx.Commit()
 }
 
 func (r *CategoryRepositoryImpl) FindAll() (*[]model.CategoryResponse, error) {
-	query := "SELECT * FROM categories WHERE deleted_at IS NULL"
+	query := categorySelectQuery + " WHERE deleted_at IS NULL"
 	rows, err1 := r.DB.QueryContext(context.Background(), query)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	defer rows.Close()
-	var categories []model.CategoryResponse
-	for rows.Next() {
-		var id int64
-		var categoryName, categorySlug string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&categoryName,
-			&categorySlug,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	return scanCategories(rows, false)
+}
 
-		if err2 != nil {
-			return nil, err2
-		}
+func (r *CategoryRepositoryImpl) FindAllWithStats() (*[]model.CategoryResponse, error) {
+	query := categoryStatsSelectQuery + " WHERE c.deleted_at IS NULL"
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return nil, err1
+	}
 
-		category := model.CategoryResponse{
-			ID:           id,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			CreatedAt:    createdAt,
-		}
+	defer rows.Close()
+	return scanCategories(rows, true)
+}
 
-		if updatedAt.Valid {
-			category.UpdatedAt = updatedAt.Time
-		}
+// ForEach streams the rows of FindAll, or FindAllWithStats, to fn.
+func (r *CategoryRepositoryImpl) ForEach(withStats bool, fn func(category *model.CategoryResponse) error) error {
+	query := categorySelectQuery + " WHERE deleted_at IS NULL"
+	if withStats {
+		query = categoryStatsSelectQuery + " WHERE c.deleted_at IS NULL"
+	}
+
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return err1
+	}
 
-		if deletedAt.Valid {
-			category.DeletedAt = deletedAt.Time
+	defer rows.Close()
+	for rows.Next() {
+		category, err2 := scanCategory(rows, withStats)
+		if err2 != nil {
+			return err2
 		}
 
-		categories = append(categories, category)
+		if err3 := fn(category); err3 != nil {
+			return err3
+		}
 	}
 
-	return &categories, nil
+	return rows.Err()
 }
 
 func (r *CategoryRepositoryImpl) FindAllSoftDeleted() (*[]model.CategoryResponse, error) {
-	query := "SELECT * FROM categories WHERE deleted_at IS NOT NULL"
+	query := categorySelectQuery + " WHERE deleted_at IS NOT NULL"
 	rows, err1 := r.DB.QueryContext(context.Background(), query)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	defer rows.Close()
-	var categories []model.CategoryResponse
-	for rows.Next() {
-		var id int64
-		var categoryName, categorySlug string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&categoryName,
-			&categorySlug,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	return scanCategories(rows, false)
+}
 
-		if err2 != nil {
-			return nil, err2
-		}
+func (r *CategoryRepositoryImpl) FindByID(categoryID int64) (*model.CategoryResponse, error) {
+	query := categorySelectQuery + " WHERE id = ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, categoryID)
+	if err1 != nil {
+		return nil, err1
+	}
 
-		category := model.CategoryResponse{
-			ID:           id,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			CreatedAt:    createdAt,
-		}
+	defer rows.Close()
+	if rows.Next() {
+		return scanCategory(rows, false)
+	}
 
-		if updatedAt.Valid {
-			category.UpdatedAt = updatedAt.Time
-		}
+	return nil, rows.Err()
+}
 
-		if deletedAt.Valid {
-			category.DeletedAt = deletedAt.Time
-		}
+func (r *CategoryRepositoryImpl) FindByIDWithStats(categoryID int64) (*model.CategoryResponse, error) {
+	query := categoryStatsSelectQuery + " WHERE c.id = ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, categoryID)
+	if err1 != nil {
+		return nil, err1
+	}
 
-		categories = append(categories, category)
+	defer rows.Close()
+	if rows.Next() {
+		return scanCategory(rows, true)
 	}
 
-	return &categories, nil
+	return nil, rows.Err()
 }
 
-func (r *CategoryRepositoryImpl) FindByID(categoryID int64) (*model.CategoryResponse, error) {
-	query := "SELECT * FROM categories WHERE id = ?"
-	rows, err1 := r.DB.QueryContext(context.Background(), query, categoryID)
+// FindAllByIDs reads the categories with the given IDs, deleted or not, in no
+// particular order. Missing IDs are left out.
+func (r *CategoryRepositoryImpl) FindAllByIDs(categoryIDs []int64, withStats bool) (*[]model.CategoryResponse, error) {
+	categories := []model.CategoryResponse{}
+	if len(categoryIDs) == 0 {
+		return &categories, nil
+	}
+
+	query := categorySelectQuery + " WHERE id IN (" + placeholders(len(categoryIDs)) + ")"
+	if withStats {
+		query = categoryStatsSelectQuery + " WHERE c.id IN (" + placeholders(len(categoryIDs)) + ")"
+	}
+
+	args := make([]interface{}, len(categoryIDs))
+	for i, categoryID := range categoryIDs {
+		args[i] = categoryID
+	}
+
+	rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	defer rows.Close()
-	if rows.Next() {
-		var id int64
-		var categoryName, categorySlug string
-		var createdAt time.Time
-		var updatedAt, deletedAt sql.NullTime
-		err2 := rows.Scan(
-			&id,
-			&categoryName,
-			&categorySlug,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		)
+	return scanCategories(rows, withStats)
+}
 
-		if err2 != nil {
-			return nil, err2
-		}
+func (r *CategoryRepositoryImpl) FindBySlug(categorySlug string) (*model.CategoryResponse, error) {
+	query := categorySelectQuery + " WHERE category_slug = ? AND deleted_at IS NULL"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, categorySlug)
+	if err1 != nil {
+		return nil, err1
+	}
 
-		category := model.CategoryResponse{
-			ID:           id,
-			CategoryName: categoryName,
-			CategorySlug: categorySlug,
-			CreatedAt:    createdAt,
-		}
+	defer rows.Close()
+	if rows.Next() {
+		return scanCategory(rows, false)
+	}
 
-		if updatedAt.Valid {
-			category.UpdatedAt = updatedAt.Time
-		}
+	return nil, rows.Err()
+}
 
-		if deletedAt.Valid {
-			category.DeletedAt = deletedAt.Time
-		}
+func (r *CategoryRepositoryImpl) FindRedirect(categorySlug string) (int64, error) {
+	query := "SELECT category_id FROM category_redirects WHERE old_slug = ?"
+	var categoryID int64
+	err := r.DB.QueryRowContext(context.Background(), query, categorySlug).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
 
-		return &category, nil
+	if err != nil {
+		return 0, err
 	}
 
-	return nil, nil
+	return categoryID, nil
 }
 
 func (r *CategoryRepositoryImpl) Update(categoryID int64, request *entity.Category) error {
-	query := "UPDATE categories SET category_name = ?, category_slug = ? WHERE id = ?"
-	result, err1 := r.DB.ExecContext(context.Background(), query, request.CategoryName, request.CategorySlug, categoryID)
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return err1
 	}
 
-	affected, err2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	query := "UPDATE categories SET category_name = ?, category_slug = ? WHERE id = ?"
+	result, err2 := tx.ExecContext(context.Background(), query, request.CategoryName, request.CategorySlug, categoryID)
 	if err2 != nil {
-		return err2
+		return duplicateEntry(err2, "a category with this name already exists")
+	}
+
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return err3
 	}
 
 	if affected != 1 {
-		return errors.New("no category updated")
+		return util.NotFoundError("no category updated")
+	}
+
+	if err4 := writeCategoryEvents(tx, model.EventCategoryUpdated, categoryID); err4 != nil {
+		return err4
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (r *CategoryRepositoryImpl) SoftDelete(categoryID int64) error {
-	query := "UPDATE categories SET deleted_at = NOW() WHERE id = ?"
-	result, e1 := r.DB.ExecContext(context.Background(), query, categoryID)
+	tx, e1 := r.DB.BeginTx(context.Background(), nil)
 	if e1 != nil {
 		return e1
 	}
 
-	affected, e2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	query := "UPDATE categories SET deleted_at = NOW() WHERE id = ?"
+	result, e2 := tx.ExecContext(context.Background(), query, categoryID)
 	if e2 != nil {
 		return e2
 	}
 
+	affected, e3 := result.RowsAffected()
+	if e3 != nil {
+		return e3
+	}
+
 	if affected != 1 {
-		return errors.New("no category deleted")
+		return util.NotFoundError("no category deleted")
 	}
 
-	return nil
+	if e4 := writeCategoryEvents(tx, model.EventCategorySoftDeleted, categoryID); e4 != nil {
+		return e4
+	}
+
+	return tx.Commit()
 }
 
 func (r *CategoryRepositoryImpl) Delete(categoryID int64) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	if err2 := writeCategoryEvents(tx, model.EventCategoryDeleted, categoryID); err2 != nil {
+		return err2
+	}
+
 	query := "DELETE FROM categories WHERE id = ?"
-	result, err1 := r.DB.ExecContext(context.Background(), query, categoryID)
+	result, err3 := tx.ExecContext(context.Background(), query, categoryID)
+	if err3 != nil {
+		return err3
+	}
+
+	affected, err4 := result.RowsAffected()
+	if err4 != nil {
+		return err4
+	}
+
+	if affected != 1 {
+		return util.NotFoundError("no category deleted")
+	}
+
+	return tx.Commit()
+}
+
+func scanCategories(rows *sql.Rows, withStats bool) (*[]model.CategoryResponse, error) {
+	var categories []model.CategoryResponse
+	for rows.Next() {
+		category, err := scanCategory(rows, withStats)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, *category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &categories, nil
+}
+
+func scanCategory(rows *sql.Rows, withStats bool) (*model.CategoryResponse, error) {
+	var id int64
+	var categoryName, categorySlug string
+	var createdAt time.Time
+	var updatedAt, deletedAt sql.NullTime
+	dest := []interface{}{
+		&id,
+		&categoryName,
+		&categorySlug,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+	}
+
+	var stats model.CategoryStats
+	var lastPublishedAt sql.NullTime
+	if withStats {
+		dest = append(dest, &stats.LiveArticles, &stats.DraftArticles, &stats.DeletedArticles, &lastPublishedAt)
+	}
+
+	err := rows.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	category := model.CategoryResponse{
+		ID:           id,
+		CategoryName: categoryName,
+		CategorySlug: categorySlug,
+		CreatedAt:    createdAt,
+	}
+
+	if updatedAt.Valid {
+		category.UpdatedAt = updatedAt.Time
+	}
+
+	if deletedAt.Valid {
+		category.DeletedAt = deletedAt.Time
+	}
+
+	if withStats {
+		if lastPublishedAt.Valid {
+			stats.LastPublishedAt = &lastPublishedAt.Time
+		}
+		category.Stats = &stats
+	}
+
+	return &category, nil
+}
+
+func (r *CategoryRepositoryImpl) Merge(sourceID int64, targetID int64) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
 	if err1 != nil {
 		return err1
 	}
 
-	affected, err2 := result.RowsAffected()
+	defer tx.Rollback()
+
+	var liveTargets int64
+	query := "SELECT COUNT(*) FROM categories WHERE id = ? AND deleted_at IS NULL"
+	err2 := tx.QueryRowContext(context.Background(), query, targetID).Scan(&liveTargets)
 	if err2 != nil {
 		return err2
 	}
 
+	if liveTargets != 1 {
+		return util.InvalidError("target category not found")
+	}
+
+	movedIDs, err3 := lockArticlesOfCategory(tx, sourceID)
+	if err3 != nil {
+		return err3
+	}
+
+	query = "UPDATE articles SET category_id = ? WHERE category_id = ?"
+	_, err4 := tx.ExecContext(context.Background(), query, targetID, sourceID)
+	if err4 != nil {
+		return err4
+	}
+
+	query = "UPDATE categories SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL"
+	result, err5 := tx.ExecContext(context.Background(), query, sourceID)
+	if err5 != nil {
+		return err5
+	}
+
+	affected, err6 := result.RowsAffected()
+	if err6 != nil {
+		return err6
+	}
+
 	if affected != 1 {
-		return errors.New("no category deleted")
+		return util.NotFoundError("no category merged")
+	}
+
+	// Slugs that already redirected to the source follow it to the target, so
+	// chains of merges never need more than one hop.
+	query = "UPDATE category_redirects SET category_id = ? WHERE category_id = ?"
+	_, err7 := tx.ExecContext(context.Background(), query, targetID, sourceID)
+	if err7 != nil {
+		return err7
+	}
+
+	query = `INSERT INTO category_redirects (old_slug, category_id)
+				SELECT category_slug, ? FROM categories WHERE id = ?
+				ON DUPLICATE KEY UPDATE category_id = VALUES(category_id)`
+	_, err8 := tx.ExecContext(context.Background(), query, targetID, sourceID)
+	if err8 != nil {
+		return err8
+	}
+
+	if err9 := writeCategoryEvents(tx, model.EventCategoryMerged, sourceID); err9 != nil {
+		return err9
+	}
+
+	if err10 := writeArticleEvents(tx, model.EventArticleMoved, movedIDs); err10 != nil {
+		return err10
+	}
+
+	return tx.Commit()
+}
+
+// lockArticlesOfCategory returns the IDs of every article of the category,
+// deleted ones included, and locks them until the transaction ends.
+func lockArticlesOfCategory(tx *sql.Tx, categoryID int64) ([]int64, error) {
+	query := "SELECT id FROM articles WHERE category_id = ? ORDER BY id FOR UPDATE"
+	rows, err1 := tx.QueryContext(context.Background(), query, categoryID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err2 := rows.Scan(&id); err2 != nil {
+			return nil, err2
+		}
+		ids = append(ids, id)
 	}
 
-	return nil
+	return ids, rows.Err()
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type CommentRepository interface {
+	Insert(request *entity.Comment) (int64, error)
+
+	FindByID(commentID int64) (*model.CommentResponse, error)
+
+	FindAllByArticle(articleID int64, status string) (*[]model.CommentResponse, error)
+
+	FindAllByStatus(status string, limit int) (*[]model.CommentResponse, error)
+
+	FindAllSoftDeleted() (*[]model.CommentResponse, error)
+
+	UpdateStatus(commentID int64, status string) error
+
+	SoftDelete(commentID int64) error
+
+	Delete(commentID int64) error
+}
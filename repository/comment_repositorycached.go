@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/cache"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+)
+
+// CachedCommentRepository does not cache comments themselves; it only keeps
+// the comment_count of cached articles in step with moderation.
+type CachedCommentRepository struct {
+	CommentRepository
+	cacheAside
+}
+
+func NewCachedCommentRepository(repo CommentRepository, c cache.Cache, counters *cache.Counters) CommentRepository {
+	return &CachedCommentRepository{
+		CommentRepository: repo,
+		cacheAside:        cacheAside{cache: c, counters: counters},
+	}
+}
+
+func (r *CachedCommentRepository) Insert(request *entity.Comment) (int64, error) {
+	id, err := r.CommentRepository.Insert(request)
+	r.invalidateArticles([]int64{request.ArticleID})
+	return id, err
+}
+
+func (r *CachedCommentRepository) UpdateStatus(commentID int64, status string) error {
+	return r.invalidateCommentArticle(commentID, func() error {
+		return r.CommentRepository.UpdateStatus(commentID, status)
+	})
+}
+
+func (r *CachedCommentRepository) SoftDelete(commentID int64) error {
+	return r.invalidateCommentArticle(commentID, func() error {
+		return r.CommentRepository.SoftDelete(commentID)
+	})
+}
+
+func (r *CachedCommentRepository) Delete(commentID int64) error {
+	return r.invalidateCommentArticle(commentID, func() error {
+		return r.CommentRepository.Delete(commentID)
+	})
+}
+
+func (r *CachedCommentRepository) invalidateCommentArticle(commentID int64, write func() error) error {
+	comment, err := r.CommentRepository.FindByID(commentID)
+	if err != nil {
+		return err
+	}
+
+	err = write()
+	if comment != nil {
+		r.invalidateArticles([]int64{comment.ArticleID})
+	}
+	return err
+}
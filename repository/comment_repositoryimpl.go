@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const commentSelectQuery = `SELECT id, article_id, parent_id, author_name, author_email, body, status, created_at, updated_at, deleted_at
+				FROM comments`
+
+type CommentRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &CommentRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *CommentRepositoryImpl) Insert(request *entity.Comment) (int64, error) {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return 0, err1
+	}
+
+	defer tx.Rollback()
+
+	query := "INSERT INTO comments (article_id, parent_id, author_name, author_email, body, status) VALUES (?, NULLIF(?, 0), ?, ?, ?, ?)"
+	result, err2 := tx.ExecContext(context.Background(), query, request.ArticleID, request.ParentID, request.AuthorName, request.AuthorEmail, request.Body, request.Status)
+	if err2 != nil {
+		return 0, err2
+	}
+
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return 0, err3
+	}
+
+	if affected != 1 {
+		return 0, errors.New("no comment saved")
+	}
+
+	id, err4 := result.LastInsertId()
+	if err4 != nil {
+		return 0, err4
+	}
+
+	if err5 := writeCommentEvents(tx, model.EventCommentCreated, id); err5 != nil {
+		return 0, err5
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *CommentRepositoryImpl) FindByID(commentID int64) (*model.CommentResponse, error) {
+	rows, err1 := r.DB.QueryContext(context.Background(), commentSelectQuery+" WHERE id = ?", commentID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		return scanComment(rows)
+	}
+
+	return nil, rows.Err()
+}
+
+// FindAllByArticle includes soft-deleted comments so that their replies can
+// still be placed in the thread.
+func (r *CommentRepositoryImpl) FindAllByArticle(articleID int64, status string) (*[]model.CommentResponse, error) {
+	query := commentSelectQuery + " WHERE article_id = ? AND status = ? ORDER BY created_at, id"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, articleID, status)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanComments(rows)
+}
+
+func (r *CommentRepositoryImpl) FindAllByStatus(status string, limit int) (*[]model.CommentResponse, error) {
+	query := commentSelectQuery + " WHERE status = ? AND deleted_at IS NULL ORDER BY created_at, id LIMIT ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, status, limit)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanComments(rows)
+}
+
+func (r *CommentRepositoryImpl) FindAllSoftDeleted() (*[]model.CommentResponse, error) {
+	query := commentSelectQuery + " WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC"
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanComments(rows)
+}
+
+func (r *CommentRepositoryImpl) UpdateStatus(commentID int64, status string) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	var exists int64
+	query := "SELECT COUNT(*) FROM comments WHERE id = ? AND deleted_at IS NULL"
+	if err2 := tx.QueryRowContext(context.Background(), query, commentID).Scan(&exists); err2 != nil {
+		return err2
+	}
+
+	if exists != 1 {
+		return errors.New("comment not found")
+	}
+
+	query = "UPDATE comments SET status = ? WHERE id = ?"
+	if _, err3 := tx.ExecContext(context.Background(), query, status, commentID); err3 != nil {
+		return err3
+	}
+
+	if err4 := writeCommentEvents(tx, model.EventCommentModerated, commentID); err4 != nil {
+		return err4
+	}
+
+	return tx.Commit()
+}
+
+func (r *CommentRepositoryImpl) SoftDelete(commentID int64) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	query := "UPDATE comments SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL"
+	result, err2 := tx.ExecContext(context.Background(), query, commentID)
+	if err2 != nil {
+		return err2
+	}
+
+	affected, err3 := result.RowsAffected()
+	if err3 != nil {
+		return err3
+	}
+
+	if affected != 1 {
+		return errors.New("no comment deleted")
+	}
+
+	if err4 := writeCommentEvents(tx, model.EventCommentSoftDeleted, commentID); err4 != nil {
+		return err4
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a comment for good; its replies move up to its parent so
+// the rest of the thread survives.
+func (r *CommentRepositoryImpl) Delete(commentID int64) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	if err2 := writeCommentEvents(tx, model.EventCommentDeleted, commentID); err2 != nil {
+		return err2
+	}
+
+	query := "UPDATE comments AS reply INNER JOIN comments AS parent ON parent.id = reply.parent_id SET reply.parent_id = parent.parent_id WHERE parent.id = ?"
+	if _, err3 := tx.ExecContext(context.Background(), query, commentID); err3 != nil {
+		return err3
+	}
+
+	query = "DELETE FROM comments WHERE id = ?"
+	result, err4 := tx.ExecContext(context.Background(), query, commentID)
+	if err4 != nil {
+		return err4
+	}
+
+	affected, err5 := result.RowsAffected()
+	if err5 != nil {
+		return err5
+	}
+
+	if affected != 1 {
+		return errors.New("no comment deleted")
+	}
+
+	return tx.Commit()
+}
+
+func scanComments(rows *sql.Rows) (*[]model.CommentResponse, error) {
+	var comments []model.CommentResponse
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, *comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &comments, nil
+}
+
+func scanComment(rows *sql.Rows) (*model.CommentResponse, error) {
+	var comment model.CommentResponse
+	var parentID sql.NullInt64
+	var createdAt time.Time
+	var updatedAt, deletedAt sql.NullTime
+	err := rows.Scan(
+		&comment.ID,
+		&comment.ArticleID,
+		&parentID,
+		&comment.AuthorName,
+		&comment.AuthorEmail,
+		&comment.Body,
+		&comment.Status,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.ParentID = parentID.Int64
+	comment.CreatedAt = createdAt
+	if updatedAt.Valid {
+		comment.UpdatedAt = updatedAt.Time
+	}
+
+	if deletedAt.Valid {
+		comment.DeletedAt = deletedAt.Time
+	}
+
+	return &comment, nil
+}
@@ -0,0 +1,7 @@
+package repository
+
+type ImportRepository interface {
+	FindMappings(source string, kind string) (map[string]int64, error)
+
+	SaveMapping(source string, kind string, externalID string, targetID int64) error
+}
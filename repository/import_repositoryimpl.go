@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Mapping kinds record which local row an item of an external export was
+// imported as, so repeated imports of the same export update nothing twice.
+const (
+	ImportKindCategory = "category"
+	ImportKindPost     = "post"
+)
+
+type ImportRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewImportRepository(db *sql.DB) ImportRepository {
+	return &ImportRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *ImportRepositoryImpl) FindMappings(source string, kind string) (map[string]int64, error) {
+	query := "SELECT external_id, target_id FROM import_mappings WHERE source = ? AND kind = ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, source, kind)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	mappings := map[string]int64{}
+	for rows.Next() {
+		var externalID string
+		var targetID int64
+		if err2 := rows.Scan(&externalID, &targetID); err2 != nil {
+			return nil, err2
+		}
+		mappings[externalID] = targetID
+	}
+
+	return mappings, rows.Err()
+}
+
+func (r *ImportRepositoryImpl) SaveMapping(source string, kind string, externalID string, targetID int64) error {
+	query := `INSERT INTO import_mappings (source, kind, external_id, target_id) VALUES (?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE target_id = VALUES(target_id)`
+	_, err1 := r.DB.ExecContext(context.Background(), query, source, kind, externalID, targetID)
+	return err1
+}
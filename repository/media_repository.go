@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type MediaRepository interface {
+	Insert(request *entity.Media) (int64, error)
+
+	FindByID(mediaID int64) (*model.MediaResponse, error)
+
+	FindBySHA256(sum string) (*model.MediaResponse, error)
+
+	FindAllByArticle(articleID int64) (*[]model.MediaResponse, error)
+
+	Attach(articleID int64, mediaID int64) error
+
+	Detach(articleID int64, mediaID int64) error
+}
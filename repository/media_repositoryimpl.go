@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const mediaSelectQuery = `SELECT m.id, m.sha256, m.content_type, m.size, m.filename, m.width, m.height, m.created_at
+				FROM media AS m`
+
+type MediaRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) MediaRepository {
+	return &MediaRepositoryImpl{
+		DB: db,
+	}
+}
+
+// Insert returns the ID of the existing row when the same content has been
+// uploaded before.
+func (r *MediaRepositoryImpl) Insert(request *entity.Media) (int64, error) {
+	query := `INSERT INTO media (sha256, content_type, size, filename, width, height) VALUES (?, ?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`
+	result, err1 := r.DB.ExecContext(context.Background(), query, request.SHA256, request.ContentType, request.Size, request.Filename, request.Width, request.Height)
+	if err1 != nil {
+		return 0, err1
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *MediaRepositoryImpl) FindByID(mediaID int64) (*model.MediaResponse, error) {
+	return r.findOne(mediaSelectQuery+" WHERE m.id = ?", mediaID)
+}
+
+func (r *MediaRepositoryImpl) FindBySHA256(sum string) (*model.MediaResponse, error) {
+	return r.findOne(mediaSelectQuery+" WHERE m.sha256 = ?", sum)
+}
+
+func (r *MediaRepositoryImpl) FindAllByArticle(articleID int64) (*[]model.MediaResponse, error) {
+	query := mediaSelectQuery + " INNER JOIN article_attachments AS aa ON aa.media_id = m.id WHERE aa.article_id = ? ORDER BY aa.created_at, m.id"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, articleID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+
+	var mediaItems []model.MediaResponse
+	for rows.Next() {
+		item, err2 := scanMedia(rows)
+		if err2 != nil {
+			return nil, err2
+		}
+		mediaItems = append(mediaItems, *item)
+	}
+
+	if err3 := rows.Err(); err3 != nil {
+		return nil, err3
+	}
+
+	return &mediaItems, nil
+}
+
+func (r *MediaRepositoryImpl) Attach(articleID int64, mediaID int64) error {
+	query := "INSERT IGNORE INTO article_attachments (article_id, media_id) VALUES (?, ?)"
+	_, err := r.DB.ExecContext(context.Background(), query, articleID, mediaID)
+	return err
+}
+
+func (r *MediaRepositoryImpl) Detach(articleID int64, mediaID int64) error {
+	query := "DELETE FROM article_attachments WHERE article_id = ? AND media_id = ?"
+	result, err1 := r.DB.ExecContext(context.Background(), query, articleID, mediaID)
+	if err1 != nil {
+		return err1
+	}
+
+	affected, err2 := result.RowsAffected()
+	if err2 != nil {
+		return err2
+	}
+
+	if affected != 1 {
+		return errors.New("no attachment deleted")
+	}
+
+	return nil
+}
+
+func (r *MediaRepositoryImpl) findOne(query string, args ...interface{}) (*model.MediaResponse, error) {
+	rows, err1 := r.DB.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		return scanMedia(rows)
+	}
+
+	return nil, rows.Err()
+}
+
+func scanMedia(rows *sql.Rows) (*model.MediaResponse, error) {
+	var item model.MediaResponse
+	err := rows.Scan(&item.ID, &item.SHA256, &item.ContentType, &item.Size, &item.Filename, &item.Width, &item.Height, &item.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	item.URL = model.MediaURL(item.ID)
+	return &item, nil
+}
+
+// deleteArticleMedia removes the attachments of hard-deleted articles along
+// with every media item they attached or featured that no other article still
+// uses, and returns the blob keys the caller should delete once the
+// transaction has committed.
+func deleteArticleMedia(tx *sql.Tx, articleIDs []interface{}) ([]string, error) {
+	in := "(" + placeholders(len(articleIDs)) + ")"
+	query := `SELECT m.id, m.sha256 FROM media AS m
+				WHERE (m.id IN (SELECT aa.media_id FROM article_attachments AS aa WHERE aa.article_id IN ` + in + `)
+					OR m.id IN (SELECT a.featured_image_id FROM articles AS a WHERE a.id IN ` + in + `))
+				AND NOT EXISTS (SELECT 1 FROM article_attachments AS other WHERE other.media_id = m.id AND other.article_id NOT IN ` + in + `)
+				AND NOT EXISTS (SELECT 1 FROM articles AS other WHERE other.featured_image_id = m.id AND other.id NOT IN ` + in + `)
+				FOR UPDATE`
+	var args []interface{}
+	for i := 0; i < 4; i++ {
+		args = append(args, articleIDs...)
+	}
+
+	rows, err1 := tx.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	var orphanIDs []interface{}
+	var keys []string
+	for rows.Next() {
+		var id int64
+		var key string
+		if err2 := rows.Scan(&id, &key); err2 != nil {
+			rows.Close()
+			return nil, err2
+		}
+		orphanIDs = append(orphanIDs, id)
+		keys = append(keys, key)
+	}
+
+	rows.Close()
+	if err3 := rows.Err(); err3 != nil {
+		return nil, err3
+	}
+
+	query = "DELETE FROM article_attachments WHERE article_id IN " + in
+	if _, err4 := tx.ExecContext(context.Background(), query, articleIDs...); err4 != nil {
+		return nil, err4
+	}
+
+	if len(orphanIDs) == 0 {
+		return nil, nil
+	}
+
+	query = "DELETE FROM media WHERE id IN (" + placeholders(len(orphanIDs)) + ")"
+	if _, err5 := tx.ExecContext(context.Background(), query, orphanIDs...); err5 != nil {
+		return nil, err5
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const outboxInsertBatchSize = 500
+
+// writeArticleEvents records an outbox event with the current snapshot of
+// each existing article, inside the caller's transaction. Deletes must call
+// it before the row is removed.
+func writeArticleEvents(tx *sql.Tx, eventType string, articleIDs []int64) error {
+	for start := 0; start < len(articleIDs); start += outboxInsertBatchSize {
+		end := start + outboxInsertBatchSize
+		if end > len(articleIDs) {
+			end = len(articleIDs)
+		}
+
+		args := make([]interface{}, end-start)
+		for i, articleID := range articleIDs[start:end] {
+			args[i] = articleID
+		}
+
+		query := articleSelect(nil) + " WHERE a.id IN (" + placeholders(len(args)) + ") ORDER BY a.id"
+		rows, err1 := tx.QueryContext(context.Background(), query, args...)
+		if err1 != nil {
+			return err1
+		}
+
+		articles, err2 := scanArticles(rows, nil)
+		rows.Close()
+		if err2 != nil {
+			return err2
+		}
+
+		events := make([]model.ChangeEvent, len(*articles))
+		for i := range *articles {
+			article := &(*articles)[i]
+			events[i] = model.ChangeEvent{Type: eventType, ArticleID: article.ID, CategoryID: article.CategoryID, Article: article}
+		}
+
+		if err3 := insertOutboxEvents(tx, events); err3 != nil {
+			return err3
+		}
+	}
+
+	return nil
+}
+
+// writeCategoryEvents is the category counterpart of writeArticleEvents.
+func writeCategoryEvents(tx *sql.Tx, eventType string, categoryIDs ...int64) error {
+	args := make([]interface{}, len(categoryIDs))
+	for i, categoryID := range categoryIDs {
+		args[i] = categoryID
+	}
+
+	query := categorySelectQuery + " WHERE id IN (" + placeholders(len(args)) + ") ORDER BY id"
+	rows, err1 := tx.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return err1
+	}
+
+	categories, err2 := scanCategories(rows, false)
+	rows.Close()
+	if err2 != nil {
+		return err2
+	}
+
+	events := make([]model.ChangeEvent, len(*categories))
+	for i := range *categories {
+		category := &(*categories)[i]
+		events[i] = model.ChangeEvent{Type: eventType, CategoryID: category.ID, Category: category}
+	}
+
+	return insertOutboxEvents(tx, events)
+}
+
+func insertOutboxEvents(tx *sql.Tx, events []model.ChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(events))
+	args := make([]interface{}, 0, len(events)*5)
+	for i, event := range events {
+		payload, err := json.Marshal(event.Message().Data)
+		if err != nil {
+			return err
+		}
+
+		values[i] = "(?, ?, ?, ?, ?)"
+		args = append(args, util.NewUUID(), event.Type, event.ArticleID, event.CategoryID, string(payload))
+	}
+
+	query := "INSERT INTO outbox (event_id, event_type, article_id, category_id, payload) VALUES " + strings.Join(values, ", ")
+	_, err := tx.ExecContext(context.Background(), query, args...)
+	return err
+}
+
+// writeCommentEvents is the comment counterpart of writeArticleEvents. Events
+// reach unauthenticated subscribers, so the author's email is left out, and
+// a comment that is not approved is described by its IDs and status only.
+func writeCommentEvents(tx *sql.Tx, eventType string, commentIDs ...int64) error {
+	args := make([]interface{}, len(commentIDs))
+	for i, commentID := range commentIDs {
+		args[i] = commentID
+	}
+
+	query := commentSelectQuery + " WHERE id IN (" + placeholders(len(args)) + ") ORDER BY id"
+	rows, err1 := tx.QueryContext(context.Background(), query, args...)
+	if err1 != nil {
+		return err1
+	}
+
+	comments, err2 := scanComments(rows)
+	rows.Close()
+	if err2 != nil {
+		return err2
+	}
+
+	events := make([]model.ChangeEvent, len(*comments))
+	for i := range *comments {
+		comment := &(*comments)[i]
+		comment.AuthorEmail = ""
+		if comment.Status != entity.CommentStatusApproved {
+			comment = &model.CommentResponse{ID: comment.ID, ArticleID: comment.ArticleID, ParentID: comment.ParentID, Status: comment.Status}
+		}
+		events[i] = model.ChangeEvent{Type: eventType, ArticleID: comment.ArticleID, Comment: comment}
+	}
+
+	return insertOutboxEvents(tx, events)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type OutboxRepository interface {
+	FindUnpublished(limit int) (*[]model.ChangeEvent, error)
+
+	MarkPublished(sequences []int64) error
+
+	DeletePublishedOlderThan(age time.Duration) (int64, error)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type OutboxRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &OutboxRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *OutboxRepositoryImpl) FindUnpublished(limit int) (*[]model.ChangeEvent, error) {
+	query := `SELECT id, event_id, event_type, article_id, category_id, payload, created_at
+				FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT ?`
+	rows, err1 := r.DB.QueryContext(context.Background(), query, limit)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+
+	var events []model.ChangeEvent
+	for rows.Next() {
+		var event model.ChangeEvent
+		var payload string
+		err2 := rows.Scan(&event.Sequence, &event.ID, &event.Type, &event.ArticleID, &event.CategoryID, &payload, &event.CreatedAt)
+		if err2 != nil {
+			return nil, err2
+		}
+
+		var err3 error
+		switch {
+		case strings.HasPrefix(event.Type, "category."):
+			err3 = json.Unmarshal([]byte(payload), &event.Category)
+		case strings.HasPrefix(event.Type, "comment."):
+			err3 = json.Unmarshal([]byte(payload), &event.Comment)
+		default:
+			err3 = json.Unmarshal([]byte(payload), &event.Article)
+		}
+		if err3 != nil {
+			return nil, err3
+		}
+
+		events = append(events, event)
+	}
+
+	if err4 := rows.Err(); err4 != nil {
+		return nil, err4
+	}
+
+	return &events, nil
+}
+
+func (r *OutboxRepositoryImpl) MarkPublished(sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(sequences))
+	for i, sequence := range sequences {
+		args[i] = sequence
+	}
+
+	query := "UPDATE outbox SET published_at = NOW() WHERE id IN (" + placeholders(len(args)) + ")"
+	_, err1 := r.DB.ExecContext(context.Background(), query, args...)
+	return err1
+}
+
+func (r *OutboxRepositoryImpl) DeletePublishedOlderThan(age time.Duration) (int64, error) {
+	query := "DELETE FROM outbox WHERE published_at < NOW() - INTERVAL ? SECOND"
+	result, err1 := r.DB.ExecContext(context.Background(), query, int64(age.Seconds()))
+	if err1 != nil {
+		return 0, err1
+	}
+
+	return result.RowsAffected()
+}
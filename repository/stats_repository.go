@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type StatsRepository interface {
+	Totals() (*model.StatsTotals, error)
+
+	ArticlesPerCategory() (*[]model.CategoryArticleCounts, error)
+
+	ArticlesCreatedPerDay(from time.Time, to time.Time) (*[]model.DailyArticleCount, error)
+
+	CountArticlesCreatedBetween(from time.Time, to time.Time) (int64, error)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type StatsRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) StatsRepository {
+	return &StatsRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *StatsRepositoryImpl) Totals() (*model.StatsTotals, error) {
+	query := `SELECT
+				(SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL),
+				COALESCE(SUM(deleted_at IS NULL AND status = 'published'), 0),
+				COALESCE(SUM(deleted_at IS NULL AND status = 'draft'), 0),
+				COALESCE(SUM(deleted_at IS NOT NULL), 0)
+				FROM articles`
+	var totals model.StatsTotals
+	err := r.DB.QueryRowContext(context.Background(), query).Scan(
+		&totals.Categories,
+		&totals.LiveArticles,
+		&totals.DraftArticles,
+		&totals.DeletedArticles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+func (r *StatsRepositoryImpl) ArticlesPerCategory() (*[]model.CategoryArticleCounts, error) {
+	query := categoryStatsSelectQuery + " WHERE c.deleted_at IS NULL ORDER BY c.id"
+	rows, err1 := r.DB.QueryContext(context.Background(), query)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	categories, err2 := scanCategories(rows, true)
+	if err2 != nil {
+		return nil, err2
+	}
+
+	counts := make([]model.CategoryArticleCounts, 0, len(*categories))
+	for _, category := range *categories {
+		counts = append(counts, model.CategoryArticleCounts{
+			CategoryID:   category.ID,
+			CategoryName: category.CategoryName,
+			CategorySlug: category.CategorySlug,
+			Stats:        *category.Stats,
+		})
+	}
+
+	return &counts, nil
+}
+
+func (r *StatsRepositoryImpl) ArticlesCreatedPerDay(from time.Time, to time.Time) (*[]model.DailyArticleCount, error) {
+	query := `SELECT DATE_FORMAT(created_at, '%Y-%m-%d') AS day, COUNT(*) FROM articles
+				WHERE created_at >= ? AND created_at < ? GROUP BY day ORDER BY day`
+	rows, err1 := r.DB.QueryContext(context.Background(), query, from, to)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	var days []model.DailyArticleCount
+	for rows.Next() {
+		var day model.DailyArticleCount
+		err2 := rows.Scan(&day.Date, &day.Count)
+		if err2 != nil {
+			return nil, err2
+		}
+
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &days, nil
+}
+
+func (r *StatsRepositoryImpl) CountArticlesCreatedBetween(from time.Time, to time.Time) (int64, error) {
+	query := "SELECT COUNT(*) FROM articles WHERE created_at >= ? AND created_at < ?"
+	var count int64
+	err := r.DB.QueryRowContext(context.Background(), query, from, to).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type WebhookRepository interface {
+	Insert(request *entity.Webhook) (int64, error)
+
+	FindAll() (*[]model.WebhookResponse, error)
+
+	FindAllActive() (*[]model.WebhookResponse, error)
+
+	FindByID(webhookID int64) (*model.WebhookResponse, error)
+
+	Update(webhookID int64, request *entity.Webhook) error
+
+	Delete(webhookID int64) error
+
+	InsertDeliveries(deliveries []entity.WebhookDelivery) error
+
+	FindDeliveries(webhookID int64, limit int) (*[]model.WebhookDeliveryResponse, error)
+
+	FindDelivery(webhookID int64, deliveryID int64) (*model.WebhookDeliveryResponse, error)
+
+	ClaimDueDeliveries(limit int, lease time.Duration) (*[]model.WebhookDeliveryResponse, error)
+
+	RecordAttempt(attempt *entity.WebhookDeliveryAttempt, status string, retryIn time.Duration) error
+
+	Redeliver(webhookID int64, deliveryID int64) error
+}
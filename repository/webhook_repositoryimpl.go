@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const webhookSelectQuery = "SELECT id, url, secret, events, active, created_at, updated_at FROM webhooks"
+
+const webhookDeliverySelectQuery = `SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, delivered_at, created_at
+				FROM webhook_deliveries`
+
+type WebhookRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *WebhookRepositoryImpl) Insert(request *entity.Webhook) (int64, error) {
+	query := "INSERT INTO webhooks (url, secret, events, active) VALUES (?, ?, ?, ?)"
+	result, err1 := r.DB.ExecContext(context.Background(), query, request.URL, request.Secret, request.Events, request.Active)
+	if err1 != nil {
+		return 0, err1
+	}
+
+	affected, err2 := result.RowsAffected()
+	if err2 != nil {
+		return 0, err2
+	}
+
+	if affected != 1 {
+		return 0, errors.New("no webhook saved")
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *WebhookRepositoryImpl) FindAll() (*[]model.WebhookResponse, error) {
+	rows, err1 := r.DB.QueryContext(context.Background(), webhookSelectQuery+" ORDER BY id")
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepositoryImpl) FindAllActive() (*[]model.WebhookResponse, error) {
+	rows, err1 := r.DB.QueryContext(context.Background(), webhookSelectQuery+" WHERE active = TRUE ORDER BY id")
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepositoryImpl) FindByID(webhookID int64) (*model.WebhookResponse, error) {
+	rows, err1 := r.DB.QueryContext(context.Background(), webhookSelectQuery+" WHERE id = ?", webhookID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		return scanWebhook(rows)
+	}
+
+	return nil, rows.Err()
+}
+
+func (r *WebhookRepositoryImpl) Update(webhookID int64, request *entity.Webhook) error {
+	query := "UPDATE webhooks SET url = ?, secret = ?, events = ?, active = ? WHERE id = ?"
+	_, err1 := r.DB.ExecContext(context.Background(), query, request.URL, request.Secret, request.Events, request.Active, webhookID)
+	return err1
+}
+
+func (r *WebhookRepositoryImpl) Delete(webhookID int64) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	query := "DELETE a FROM webhook_delivery_attempts AS a INNER JOIN webhook_deliveries AS d ON d.id = a.delivery_id WHERE d.webhook_id = ?"
+	if _, err2 := tx.ExecContext(context.Background(), query, webhookID); err2 != nil {
+		return err2
+	}
+
+	query = "DELETE FROM webhook_deliveries WHERE webhook_id = ?"
+	if _, err3 := tx.ExecContext(context.Background(), query, webhookID); err3 != nil {
+		return err3
+	}
+
+	query = "DELETE FROM webhooks WHERE id = ?"
+	result, err4 := tx.ExecContext(context.Background(), query, webhookID)
+	if err4 != nil {
+		return err4
+	}
+
+	affected, err5 := result.RowsAffected()
+	if err5 != nil {
+		return err5
+	}
+
+	if affected != 1 {
+		return errors.New("no webhook deleted")
+	}
+
+	return tx.Commit()
+}
+
+func (r *WebhookRepositoryImpl) InsertDeliveries(deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(deliveries))
+	args := make([]interface{}, 0, len(deliveries)*4)
+	for i, delivery := range deliveries {
+		values[i] = "(?, ?, ?, ?, 'pending', NOW())"
+		args = append(args, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload)
+	}
+
+	// Deliveries already queued for the same webhook and event are skipped.
+	query := "INSERT IGNORE INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at) VALUES " + strings.Join(values, ", ")
+	_, err1 := r.DB.ExecContext(context.Background(), query, args...)
+	return err1
+}
+
+func (r *WebhookRepositoryImpl) FindDeliveries(webhookID int64, limit int) (*[]model.WebhookDeliveryResponse, error) {
+	query := webhookDeliverySelectQuery + " WHERE webhook_id = ? ORDER BY id DESC LIMIT ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, webhookID, limit)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *WebhookRepositoryImpl) FindDelivery(webhookID int64, deliveryID int64) (*model.WebhookDeliveryResponse, error) {
+	query := webhookDeliverySelectQuery + " WHERE webhook_id = ? AND id = ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, webhookID, deliveryID)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	delivery, err2 := scanWebhookDelivery(rows)
+	if err2 != nil {
+		return nil, err2
+	}
+	rows.Close()
+
+	query = `SELECT id, response_status, error, duration_ms, created_at
+				FROM webhook_delivery_attempts WHERE delivery_id = ? ORDER BY id`
+	attemptRows, err3 := r.DB.QueryContext(context.Background(), query, deliveryID)
+	if err3 != nil {
+		return nil, err3
+	}
+
+	defer attemptRows.Close()
+	for attemptRows.Next() {
+		var attempt model.WebhookAttemptResponse
+		var responseStatus sql.NullInt64
+		var attemptErr sql.NullString
+		err4 := attemptRows.Scan(&attempt.ID, &responseStatus, &attemptErr, &attempt.DurationMS, &attempt.CreatedAt)
+		if err4 != nil {
+			return nil, err4
+		}
+
+		attempt.ResponseStatus = int(responseStatus.Int64)
+		attempt.Error = attemptErr.String
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	return delivery, attemptRows.Err()
+}
+
+// ClaimDueDeliveries leases pending deliveries that are due by pushing their
+// next attempt into the future, so a worker that dies mid-delivery leaves
+// them to be retried once the lease runs out.
+func (r *WebhookRepositoryImpl) ClaimDueDeliveries(limit int, lease time.Duration) (*[]model.WebhookDeliveryResponse, error) {
+	query := "SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= NOW() ORDER BY next_attempt_at, id LIMIT ?"
+	rows, err1 := r.DB.QueryContext(context.Background(), query, limit)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	var due []int64
+	for rows.Next() {
+		var id int64
+		if err2 := rows.Scan(&id); err2 != nil {
+			rows.Close()
+			return nil, err2
+		}
+		due = append(due, id)
+	}
+	rows.Close()
+
+	var claimed []interface{}
+	query = "UPDATE webhook_deliveries SET next_attempt_at = NOW() + INTERVAL ? SECOND WHERE id = ? AND status = 'pending' AND next_attempt_at <= NOW()"
+	for _, id := range due {
+		result, err3 := r.DB.ExecContext(context.Background(), query, int64(lease.Seconds()), id)
+		if err3 != nil {
+			return nil, err3
+		}
+
+		affected, err4 := result.RowsAffected()
+		if err4 != nil {
+			return nil, err4
+		}
+
+		if affected == 1 {
+			claimed = append(claimed, id)
+		}
+	}
+
+	if len(claimed) == 0 {
+		return &[]model.WebhookDeliveryResponse{}, nil
+	}
+
+	query = webhookDeliverySelectQuery + " WHERE id IN (" + placeholders(len(claimed)) + ") ORDER BY id"
+	claimedRows, err5 := r.DB.QueryContext(context.Background(), query, claimed...)
+	if err5 != nil {
+		return nil, err5
+	}
+
+	defer claimedRows.Close()
+	return scanWebhookDeliveries(claimedRows)
+}
+
+func (r *WebhookRepositoryImpl) RecordAttempt(attempt *entity.WebhookDeliveryAttempt, status string, retryIn time.Duration) error {
+	tx, err1 := r.DB.BeginTx(context.Background(), nil)
+	if err1 != nil {
+		return err1
+	}
+
+	defer tx.Rollback()
+
+	query := "INSERT INTO webhook_delivery_attempts (delivery_id, response_status, error, duration_ms) VALUES (?, NULLIF(?, 0), NULLIF(?, ''), ?)"
+	_, err2 := tx.ExecContext(context.Background(), query, attempt.DeliveryID, attempt.ResponseStatus, attempt.Error, attempt.DurationMS)
+	if err2 != nil {
+		return err2
+	}
+
+	query = `UPDATE webhook_deliveries SET attempts = attempts + 1, status = ?,
+				next_attempt_at = IF(? = 'pending', NOW() + INTERVAL ? SECOND, NULL),
+				delivered_at = IF(? = 'succeeded', NOW(), delivered_at)
+				WHERE id = ?`
+	result, err3 := tx.ExecContext(context.Background(), query, status, status, int64(retryIn.Seconds()), status, attempt.DeliveryID)
+	if err3 != nil {
+		return err3
+	}
+
+	affected, err4 := result.RowsAffected()
+	if err4 != nil {
+		return err4
+	}
+
+	if affected != 1 {
+		return errors.New("no webhook delivery updated")
+	}
+
+	return tx.Commit()
+}
+
+func (r *WebhookRepositoryImpl) Redeliver(webhookID int64, deliveryID int64) error {
+	query := "UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL WHERE webhook_id = ? AND id = ?"
+	result, err1 := r.DB.ExecContext(context.Background(), query, webhookID, deliveryID)
+	if err1 != nil {
+		return err1
+	}
+
+	affected, err2 := result.RowsAffected()
+	if err2 != nil {
+		return err2
+	}
+
+	if affected != 1 {
+		return errors.New("webhook delivery not found")
+	}
+
+	return nil
+}
+
+func scanWebhooks(rows *sql.Rows) (*[]model.WebhookResponse, error) {
+	var webhooks []model.WebhookResponse
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &webhooks, nil
+}
+
+func scanWebhook(rows *sql.Rows) (*model.WebhookResponse, error) {
+	var webhook model.WebhookResponse
+	var events string
+	var updatedAt sql.NullTime
+	err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = strings.Split(events, ",")
+	if updatedAt.Valid {
+		webhook.UpdatedAt = updatedAt.Time
+	}
+
+	return &webhook, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) (*[]model.WebhookDeliveryResponse, error) {
+	var deliveries []model.WebhookDeliveryResponse
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &deliveries, nil
+}
+
+func scanWebhookDelivery(rows *sql.Rows) (*model.WebhookDeliveryResponse, error) {
+	var delivery model.WebhookDeliveryResponse
+	var payload string
+	var nextAttemptAt, deliveredAt sql.NullTime
+	err := rows.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&deliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = json.RawMessage(payload)
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
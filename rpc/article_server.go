@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/pb"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type ArticleServer struct {
+	pb.UnimplementedArticleServiceServer
+
+	articleService service.ArticleService
+}
+
+func NewArticleServer(articleService *service.ArticleService) *ArticleServer {
+	return &ArticleServer{articleService: *articleService}
+}
+
+func (server *ArticleServer) CreateArticle(ctx context.Context, request *pb.CreateArticleRequest) (*pb.Article, error) {
+	article := server.articleService.Create(&model.ArticleCreateRequest{
+		Title:           request.Title,
+		CategoryID:      request.CategoryId,
+		Content:         request.Content,
+		ContentFormat:   request.ContentFormat,
+		Status:          fromArticleStatus(request.Status),
+		FeaturedImageID: request.FeaturedImageId,
+	})
+	return toArticle(article), nil
+}
+
+func (server *ArticleServer) GetArticle(ctx context.Context, request *pb.GetArticleRequest) (*pb.Article, error) {
+	var article *model.ArticleResponse
+	switch key := request.Key.(type) {
+	case *pb.GetArticleRequest_Id:
+		article = server.articleService.FindOne(formatID(key.Id), nil)
+	case *pb.GetArticleRequest_Slug:
+		article = server.articleService.FindOneBySlug(key.Slug, nil)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or slug is required")
+	}
+
+	if article == nil {
+		return nil, status.Error(codes.NotFound, "article not found")
+	}
+	return toArticle(article), nil
+}
+
+func (server *ArticleServer) ListArticles(request *pb.ListArticlesRequest, stream pb.ArticleService_ListArticlesServer) error {
+	return server.articleService.Stream(request.Title, listSelection(request.IncludeContent), func(article *model.ArticleResponse) error {
+		return stream.Send(toArticle(article))
+	})
+}
+
+func (server *ArticleServer) ListDeletedArticles(ctx context.Context, request *pb.ListDeletedArticlesRequest) (*pb.ListArticlesResponse, error) {
+	articles := server.articleService.ListSoftDeleted(listSelection(request.IncludeContent))
+	return &pb.ListArticlesResponse{Articles: toArticles(articles)}, nil
+}
+
+func (server *ArticleServer) UpdateArticle(ctx context.Context, request *pb.UpdateArticleRequest) (*pb.Article, error) {
+	article := server.articleService.Update(formatID(request.Id), &model.ArticleUpdateRequest{
+		Title:           request.Title,
+		CategoryID:      request.CategoryId,
+		Content:         request.Content,
+		ContentFormat:   request.ContentFormat,
+		Status:          fromArticleStatus(request.Status),
+		FeaturedImageID: request.FeaturedImageId,
+	})
+	return toArticle(article), nil
+}
+
+func (server *ArticleServer) SoftDeleteArticle(ctx context.Context, request *pb.DeleteArticleRequest) (*emptypb.Empty, error) {
+	server.articleService.SoftDelete(formatID(request.Id))
+	return &emptypb.Empty{}, nil
+}
+
+func (server *ArticleServer) DeleteArticle(ctx context.Context, request *pb.DeleteArticleRequest) (*emptypb.Empty, error) {
+	server.articleService.Delete(formatID(request.Id))
+	return &emptypb.Empty{}, nil
+}
+
+func (server *ArticleServer) MoveArticles(ctx context.Context, request *pb.MoveArticlesRequest) (*pb.MoveArticlesResponse, error) {
+	moved := server.articleService.MoveToCategory(&model.ArticleMoveRequest{
+		ArticleIDs: request.ArticleIds,
+		CategoryID: request.CategoryId,
+	})
+	return &pb.MoveArticlesResponse{CategoryId: moved.CategoryID, Moved: moved.Moved}, nil
+}
+
+// listSelection leaves the content out of listings unless asked for, as the
+// REST listings do.
+func listSelection(includeContent bool) *model.Selection {
+	if includeContent {
+		return &model.Selection{Fields: model.ArticleFields}
+	}
+
+	selection := &model.Selection{}
+	for _, field := range model.ArticleFields {
+		if !isContentField(field) {
+			selection.Fields = append(selection.Fields, field)
+		}
+	}
+	return selection
+}
+
+func isContentField(field string) bool {
+	for _, contentField := range model.ArticleContentFields {
+		if field == contentField {
+			return true
+		}
+	}
+	return false
+}
+
+// formatID hands IDs to the services in the form they take them from URLs.
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/pb"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type CategoryServer struct {
+	pb.UnimplementedCategoryServiceServer
+
+	categoryService service.CategoryService
+}
+
+func NewCategoryServer(categoryService *service.CategoryService) *CategoryServer {
+	return &CategoryServer{categoryService: *categoryService}
+}
+
+func (server *CategoryServer) CreateCategory(ctx context.Context, request *pb.CreateCategoryRequest) (*pb.Category, error) {
+	category := server.categoryService.Create(&model.CategoryCreateRequest{CategoryName: request.CategoryName})
+	return toCategory(category), nil
+}
+
+func (server *CategoryServer) GetCategory(ctx context.Context, request *pb.GetCategoryRequest) (*pb.Category, error) {
+	var category *model.CategoryResponse
+	switch key := request.Key.(type) {
+	case *pb.GetCategoryRequest_Id:
+		if request.WithStats {
+			category = server.categoryService.FindOneWithStats(formatID(key.Id))
+		} else {
+			category = server.categoryService.FindOne(formatID(key.Id))
+		}
+	case *pb.GetCategoryRequest_Slug:
+		category = server.categoryService.FindOneBySlug(key.Slug)
+		if category != nil && request.WithStats {
+			category = server.categoryService.FindOneWithStats(formatID(category.ID))
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or slug is required")
+	}
+
+	if category == nil {
+		return nil, status.Error(codes.NotFound, "category not found")
+	}
+	return toCategory(category), nil
+}
+
+func (server *CategoryServer) ListCategories(ctx context.Context, request *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	var categories *[]model.CategoryResponse
+	if request.WithStats {
+		categories = server.categoryService.ListWithStats()
+	} else {
+		categories = server.categoryService.List()
+	}
+	return &pb.ListCategoriesResponse{Categories: toCategories(categories)}, nil
+}
+
+func (server *CategoryServer) ListDeletedCategories(ctx context.Context, request *pb.ListDeletedCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	categories := server.categoryService.ListSoftDeleted()
+	return &pb.ListCategoriesResponse{Categories: toCategories(categories)}, nil
+}
+
+func (server *CategoryServer) UpdateCategory(ctx context.Context, request *pb.UpdateCategoryRequest) (*pb.Category, error) {
+	category := server.categoryService.Update(formatID(request.Id), &model.CategoryUpdateRequest{CategoryName: request.CategoryName})
+	return toCategory(category), nil
+}
+
+func (server *CategoryServer) SoftDeleteCategory(ctx context.Context, request *pb.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	server.categoryService.SoftDelete(formatID(request.Id))
+	return &emptypb.Empty{}, nil
+}
+
+func (server *CategoryServer) DeleteCategory(ctx context.Context, request *pb.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	server.categoryService.Delete(formatID(request.Id))
+	return &emptypb.Empty{}, nil
+}
+
+func (server *CategoryServer) MergeCategory(ctx context.Context, request *pb.MergeCategoryRequest) (*pb.Category, error) {
+	category := server.categoryService.Merge(formatID(request.Id), &model.CategoryMergeRequest{TargetID: request.TargetId})
+	return toCategory(category), nil
+}
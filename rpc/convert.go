@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toArticle(article *model.ArticleResponse) *pb.Article {
+	message := &pb.Article{
+		Id:                 article.ID,
+		Title:              article.Title,
+		Slug:               article.Slug,
+		CategoryId:         article.CategoryID,
+		CategoryName:       article.CategoryName,
+		CategorySlug:       article.CategorySlug,
+		Content:            article.Content,
+		ContentFormat:      article.ContentFormat,
+		ContentHtml:        article.ContentHTML,
+		Excerpt:            article.Excerpt,
+		WordCount:          int32(article.WordCount),
+		ReadingTimeMinutes: int32(article.ReadingTime),
+		CommentCount:       article.CommentCount,
+		Status:             toArticleStatus(article.Status),
+		FeaturedImageId:    article.FeaturedImageID,
+		PublishedAt:        toTimestamp(article.PublishedAt),
+		CreatedAt:          toTimestamp(article.CreatedAt),
+		UpdatedAt:          toTimestamp(article.UpdatedAt),
+		DeletedAt:          toTimestamp(article.DeletedAt),
+	}
+
+	for _, entry := range article.TOC {
+		message.Toc = append(message.Toc, &pb.TocEntry{Level: int32(entry.Level), Id: entry.ID, Title: entry.Title})
+	}
+
+	if image := article.FeaturedImage; image != nil {
+		message.FeaturedImage = &pb.Image{
+			Id:       image.ID,
+			Width:    int32(image.Width),
+			Height:   int32(image.Height),
+			Url:      image.URL,
+			Srcset:   image.Srcset,
+			Variants: image.Variants,
+		}
+	}
+
+	return message
+}
+
+func toArticles(articles *[]model.ArticleResponse) []*pb.Article {
+	if articles == nil {
+		return nil
+	}
+
+	messages := make([]*pb.Article, 0, len(*articles))
+	for i := range *articles {
+		messages = append(messages, toArticle(&(*articles)[i]))
+	}
+	return messages
+}
+
+func toArticleStatus(status string) pb.ArticleStatus {
+	switch status {
+	case entity.ArticleStatusPublished:
+		return pb.ArticleStatus_ARTICLE_STATUS_PUBLISHED
+	case entity.ArticleStatusDraft:
+		return pb.ArticleStatus_ARTICLE_STATUS_DRAFT
+	default:
+		return pb.ArticleStatus_ARTICLE_STATUS_UNSPECIFIED
+	}
+}
+
+// fromArticleStatus leaves an unspecified status empty for the service to
+// default, and passes unknown values on for it to reject.
+func fromArticleStatus(status pb.ArticleStatus) string {
+	switch status {
+	case pb.ArticleStatus_ARTICLE_STATUS_UNSPECIFIED:
+		return ""
+	case pb.ArticleStatus_ARTICLE_STATUS_PUBLISHED:
+		return entity.ArticleStatusPublished
+	case pb.ArticleStatus_ARTICLE_STATUS_DRAFT:
+		return entity.ArticleStatusDraft
+	default:
+		return status.String()
+	}
+}
+
+func toCategory(category *model.CategoryResponse) *pb.Category {
+	message := &pb.Category{
+		Id:           category.ID,
+		CategoryName: category.CategoryName,
+		CategorySlug: category.CategorySlug,
+		CreatedAt:    toTimestamp(category.CreatedAt),
+		UpdatedAt:    toTimestamp(category.UpdatedAt),
+		DeletedAt:    toTimestamp(category.DeletedAt),
+	}
+
+	if stats := category.Stats; stats != nil {
+		message.Stats = &pb.CategoryStats{
+			LiveArticles:    stats.LiveArticles,
+			DraftArticles:   stats.DraftArticles,
+			DeletedArticles: stats.DeletedArticles,
+		}
+		if stats.LastPublishedAt != nil {
+			message.Stats.LastPublishedAt = toTimestamp(*stats.LastPublishedAt)
+		}
+	}
+
+	return message
+}
+
+func toCategories(categories *[]model.CategoryResponse) []*pb.Category {
+	if categories == nil {
+		return nil
+	}
+
+	messages := make([]*pb.Category, 0, len(*categories))
+	for i := range *categories {
+		messages = append(messages, toCategory(&(*categories)[i]))
+	}
+	return messages
+}
+
+// toTimestamp leaves zero times, such as the deleted_at of a live article,
+// unset.
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
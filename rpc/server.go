@@ -0,0 +1,25 @@
+// Package rpc serves the article and category services over gRPC, next to
+// the REST API and on a port of its own.
+package rpc
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/pb"
+	"github.com/muhammadrijalkamal/backendtest/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer registers both services, with reflection so that tools such as
+// grpcurl can list them. The services report errors by panicking; the
+// interceptors recover those and answer with a matching status.
+func NewServer(articleService *service.ArticleService, categoryService *service.CategoryService) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryStatus),
+		grpc.StreamInterceptor(streamStatus),
+	)
+
+	pb.RegisterArticleServiceServer(server, NewArticleServer(articleService))
+	pb.RegisterCategoryServiceServer(server, NewCategoryServer(categoryService))
+	reflection.Register(server)
+	return server
+}
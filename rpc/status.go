@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/muhammadrijalkamal/backendtest/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError maps the errors of the services to gRPC statuses. Errors the
+// client did not cause are logged and answered with a bare Internal, so that
+// database details stay on the server.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var numErr *strconv.NumError
+	switch {
+	case util.KindOf(err) == util.ErrorInvalid, errors.As(err, &numErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case util.KindOf(err) == util.ErrorNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case util.KindOf(err) == util.ErrorConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	log.Println("grpc:", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// recovered turns the panic the services report errors with into a status.
+func recovered(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return statusError(err)
+	}
+	return statusError(fmt.Errorf("%v", r))
+}
+
+func unaryStatus(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			response, err = nil, recovered(r)
+		}
+	}()
+
+	response, err = handler(ctx, request)
+	if err != nil {
+		err = statusError(err)
+	}
+	return response, err
+}
+
+func streamStatus(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(r)
+		}
+	}()
+
+	if err = handler(server, stream); err != nil {
+		err = statusError(err)
+	}
+	return err
+}
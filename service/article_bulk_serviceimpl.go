@@ -0,0 +1,248 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gosimple/slug"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const (
+	bulkMaxItems  = 10000
+	bulkBatchSize = 500
+)
+
+func (service *ArticleServiceImpl) CreateBulk(items []json.RawMessage, mode string) *model.BulkResponse {
+	atomic := bulkAtomic(mode)
+	if len(items) > bulkMaxItems {
+		panic(util.InvalidError(fmt.Sprintf("bulk requests are limited to %d items", bulkMaxItems)))
+	}
+
+	response := newBulkResponse(mode, len(items))
+
+	parsed := make([]*entity.Article, len(items))
+	var candidates []string
+	for i, item := range items {
+		article, err := bulkArticle(item)
+		if err != nil {
+			response.fail(i, err)
+			continue
+		}
+
+		parsed[i] = article
+		candidates = append(candidates, article.Slug)
+	}
+
+	existing, txErr := service.articleRepository.FindIDsBySlugs(candidates)
+	util.ReturnErrorIfNeeded(txErr)
+
+	// A slug that is taken fails only the item that came later: the stored
+	// article, or the first item of the request that uses it, is kept.
+	var articles []entity.Article
+	var indexes []int
+	slugs := map[string]int{}
+	for i, article := range parsed {
+		if article == nil {
+			continue
+		}
+
+		if id, ok := existing[article.Slug]; ok {
+			response.fail(i, fmt.Errorf("slug %q is already used by article %d", article.Slug, id))
+			continue
+		}
+		if other, ok := slugs[article.Slug]; ok {
+			response.fail(i, fmt.Errorf("slug %q is already used by item %d", article.Slug, other))
+			continue
+		}
+
+		slugs[article.Slug] = i
+		articles = append(articles, *article)
+		indexes = append(indexes, i)
+	}
+
+	if atomic {
+		if response.Failed > 0 {
+			response.rollBack(indexes, nil)
+			return &response.BulkResponse
+		}
+
+		ids, txErr := service.articleRepository.InsertMany(articles)
+		if txErr != nil {
+			response.rollBack(indexes, txErr)
+			return &response.BulkResponse
+		}
+
+		for i, index := range indexes {
+			response.succeed(index, ids[i], model.BulkStatusCreated)
+		}
+
+		return &response.BulkResponse
+	}
+
+	for start := 0; start < len(articles); start += bulkBatchSize {
+		end := start + bulkBatchSize
+		if end > len(articles) {
+			end = len(articles)
+		}
+
+		ids, txErr := service.articleRepository.InsertMany(articles[start:end])
+		if txErr == nil {
+			for i, index := range indexes[start:end] {
+				response.succeed(index, ids[i], model.BulkStatusCreated)
+			}
+			continue
+		}
+
+		// The batch failed as a whole; retry its items one by one so a single
+		// bad row only fails itself.
+		for i, index := range indexes[start:end] {
+			id, txErr := service.articleRepository.Insert(&articles[start+i])
+			if txErr != nil {
+				response.fail(index, txErr)
+				continue
+			}
+			response.succeed(index, id, model.BulkStatusCreated)
+		}
+	}
+
+	return &response.BulkResponse
+}
+
+func (service *ArticleServiceImpl) SoftDeleteBulk(request *model.BulkDeleteRequest, mode string) *model.BulkResponse {
+	return service.deleteBulk(request, mode, service.articleRepository.SoftDeleteMany)
+}
+
+func (service *ArticleServiceImpl) DeleteBulk(request *model.BulkDeleteRequest, mode string) *model.BulkResponse {
+	return service.deleteBulk(request, mode, service.articleRepository.DeleteMany)
+}
+
+func (service *ArticleServiceImpl) deleteBulk(request *model.BulkDeleteRequest, mode string, deleteMany func([]int64, bool) ([]int64, error)) *model.BulkResponse {
+	atomic := bulkAtomic(mode)
+	if len(request.IDs) > bulkMaxItems {
+		panic(util.InvalidError(fmt.Sprintf("bulk requests are limited to %d items", bulkMaxItems)))
+	}
+
+	response := newBulkResponse(mode, len(request.IDs))
+	indexes := make([]int, len(request.IDs))
+	for i := range request.IDs {
+		indexes[i] = i
+	}
+
+	deleted, txErr := deleteMany(request.IDs, atomic)
+	if txErr != nil {
+		if atomic {
+			response.rollBack(indexes, txErr)
+			return &response.BulkResponse
+		}
+		for _, index := range indexes {
+			response.fail(index, txErr)
+		}
+		return &response.BulkResponse
+	}
+
+	found := map[int64]bool{}
+	for _, id := range deleted {
+		found[id] = true
+	}
+
+	for i, id := range request.IDs {
+		if found[id] {
+			response.succeed(i, id, model.BulkStatusDeleted)
+		} else {
+			response.fail(i, errors.New("article not found"))
+		}
+	}
+
+	return &response.BulkResponse
+}
+
+type bulkResponse struct {
+	model.BulkResponse
+}
+
+func newBulkResponse(mode string, size int) *bulkResponse {
+	if mode == "" {
+		mode = model.BulkModeAtomic
+	}
+
+	items := make([]model.BulkItemResult, size)
+	for i := range items {
+		items[i].Index = i
+	}
+
+	return &bulkResponse{model.BulkResponse{Mode: mode, Items: items}}
+}
+
+func (r *bulkResponse) succeed(index int, id int64, status string) {
+	r.Items[index].ID = id
+	r.Items[index].Status = status
+	r.Succeeded++
+}
+
+func (r *bulkResponse) fail(index int, err error) {
+	r.Items[index].Status = model.BulkStatusFailed
+	r.Items[index].Error = err.Error()
+	r.Failed++
+}
+
+// rollBack marks the given items as rolled back after an atomic operation was
+// aborted. Items that already failed validation keep their own error.
+func (r *bulkResponse) rollBack(indexes []int, err error) {
+	for _, index := range indexes {
+		r.Items[index].Status = model.BulkStatusRolledBack
+		if err != nil {
+			r.Items[index].Error = err.Error()
+		}
+		r.Failed++
+	}
+}
+
+func bulkAtomic(mode string) bool {
+	switch mode {
+	case "", model.BulkModeAtomic:
+		return true
+	case model.BulkModeBestEffort:
+		return false
+	default:
+		panic(util.InvalidError("invalid bulk mode"))
+	}
+}
+
+func bulkArticle(item json.RawMessage) (*entity.Article, error) {
+	var request model.ArticleCreateRequest
+	if err := json.Unmarshal(item, &request); err != nil {
+		return nil, err
+	}
+
+	if request.Title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	if request.CategoryID <= 0 {
+		return nil, errors.New("category_id is required")
+	}
+
+	status, err := validateArticleStatus(request.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	article := entity.Article{
+		Title:           request.Title,
+		Slug:            slug.Make(request.Title),
+		CategoryID:      request.CategoryID,
+		Content:         request.Content,
+		Status:          status,
+		FeaturedImageID: request.FeaturedImageID,
+	}
+
+	if err := renderArticleContent(&article, request.ContentFormat); err != nil {
+		return nil, err
+	}
+
+	return &article, nil
+}
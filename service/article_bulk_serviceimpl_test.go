@@ -0,0 +1,200 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+)
+
+// bulkArticleRepository stores inserted articles in memory. Inserting an
+// article whose slug is failSlug fails, as a database error would.
+type bulkArticleRepository struct {
+	repository.ArticleRepository
+
+	existing map[string]int64
+	failSlug string
+	inserted []string
+	nextID   int64
+}
+
+func (r *bulkArticleRepository) Insert(request *entity.Article) (int64, error) {
+	ids, err := r.InsertMany([]entity.Article{*request})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *bulkArticleRepository) InsertMany(requests []entity.Article) ([]int64, error) {
+	for _, request := range requests {
+		if request.Slug == r.failSlug {
+			return nil, errors.New("insert failed")
+		}
+	}
+
+	ids := make([]int64, len(requests))
+	for i, request := range requests {
+		r.nextID++
+		ids[i] = r.nextID
+		r.inserted = append(r.inserted, request.Slug)
+	}
+	return ids, nil
+}
+
+func (r *bulkArticleRepository) FindIDsBySlugs(slugs []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	for _, slug := range slugs {
+		if id, ok := r.existing[slug]; ok {
+			ids[slug] = id
+		}
+	}
+	return ids, nil
+}
+
+func TestCreateBulk(t *testing.T) {
+	type result struct {
+		status string
+		err    string
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		items    []string
+		existing map[string]int64
+		failSlug string
+		want     []result
+		inserted []string
+	}{
+		{
+			name:     "atomic, all valid",
+			mode:     model.BulkModeAtomic,
+			items:    []string{`{"title": "One", "category_id": 1}`, `{"title": "Two", "category_id": 1}`},
+			want:     []result{{model.BulkStatusCreated, ""}, {model.BulkStatusCreated, ""}},
+			inserted: []string{"one", "two"},
+		},
+		{
+			name:  "atomic, mixed",
+			mode:  model.BulkModeAtomic,
+			items: []string{`{"title": "One", "category_id": 1}`, `{"category_id": 1}`, `{"title": "Three"}`, `{"title": "Four", "category_id": 1, "status": "archived"}`},
+			want: []result{
+				{model.BulkStatusRolledBack, ""},
+				{model.BulkStatusFailed, "title is required"},
+				{model.BulkStatusFailed, "category_id is required"},
+				{model.BulkStatusFailed, "invalid article status"},
+			},
+		},
+		{
+			name:  "best effort, mixed",
+			mode:  model.BulkModeBestEffort,
+			items: []string{`{"title": "One", "category_id": 1}`, `not json`, `{"title": "Three", "category_id": 1}`},
+			want: []result{
+				{model.BulkStatusCreated, ""},
+				{model.BulkStatusFailed, "invalid character"},
+				{model.BulkStatusCreated, ""},
+			},
+			inserted: []string{"one", "three"},
+		},
+		{
+			name:  "atomic, duplicate slugs",
+			mode:  model.BulkModeAtomic,
+			items: []string{`{"title": "Same", "category_id": 1}`, `{"title": "Other", "category_id": 1}`, `{"title": "same", "category_id": 2}`},
+			want: []result{
+				{model.BulkStatusRolledBack, ""},
+				{model.BulkStatusRolledBack, ""},
+				{model.BulkStatusFailed, `slug "same" is already used by item 0`},
+			},
+		},
+		{
+			name:  "best effort, duplicate slugs",
+			mode:  model.BulkModeBestEffort,
+			items: []string{`{"title": "Same", "category_id": 1}`, `{"title": "Same", "category_id": 1}`, `{"title": "Same!", "category_id": 1}`},
+			want: []result{
+				{model.BulkStatusCreated, ""},
+				{model.BulkStatusFailed, `slug "same" is already used by item 0`},
+				{model.BulkStatusFailed, `slug "same" is already used by item 0`},
+			},
+			inserted: []string{"same"},
+		},
+		{
+			name:     "best effort, slug of a stored article",
+			mode:     model.BulkModeBestEffort,
+			items:    []string{`{"title": "Taken", "category_id": 1}`, `{"title": "Free", "category_id": 1}`},
+			existing: map[string]int64{"taken": 7},
+			want: []result{
+				{model.BulkStatusFailed, `slug "taken" is already used by article 7`},
+				{model.BulkStatusCreated, ""},
+			},
+			inserted: []string{"free"},
+		},
+		{
+			name:     "atomic, insert fails",
+			mode:     model.BulkModeAtomic,
+			items:    []string{`{"title": "One", "category_id": 1}`, `{"title": "Bad", "category_id": 1}`},
+			failSlug: "bad",
+			want:     []result{{model.BulkStatusRolledBack, "insert failed"}, {model.BulkStatusRolledBack, "insert failed"}},
+		},
+		{
+			name:     "best effort, insert fails",
+			mode:     model.BulkModeBestEffort,
+			items:    []string{`{"title": "One", "category_id": 1}`, `{"title": "Bad", "category_id": 1}`, `{"title": "Three", "category_id": 1}`},
+			failSlug: "bad",
+			want: []result{
+				{model.BulkStatusCreated, ""},
+				{model.BulkStatusFailed, "insert failed"},
+				{model.BulkStatusCreated, ""},
+			},
+			inserted: []string{"one", "three"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &bulkArticleRepository{existing: test.existing, failSlug: test.failSlug}
+			var articleRepository repository.ArticleRepository = repo
+			service := NewArticleService(&articleRepository, nil)
+
+			items := make([]json.RawMessage, len(test.items))
+			for i, item := range test.items {
+				items[i] = json.RawMessage(item)
+			}
+
+			response := service.CreateBulk(items, test.mode)
+			if response.Mode != test.mode {
+				t.Errorf("mode = %q, want %q", response.Mode, test.mode)
+			}
+			if len(response.Items) != len(test.want) {
+				t.Fatalf("got %d results, want %d", len(response.Items), len(test.want))
+			}
+
+			succeeded := 0
+			for i, want := range test.want {
+				got := response.Items[i]
+				if got.Index != i || got.Status != want.status {
+					t.Errorf("item %d: index %d, status %q, want status %q", i, got.Index, got.Status, want.status)
+				}
+				if want.err == "" && got.Error != "" || !strings.Contains(got.Error, want.err) {
+					t.Errorf("item %d: error %q, want %q", i, got.Error, want.err)
+				}
+				if want.status == model.BulkStatusCreated {
+					succeeded++
+					if got.ID == 0 {
+						t.Errorf("item %d: created without an ID", i)
+					}
+				}
+			}
+
+			if response.Succeeded != succeeded || response.Failed != len(test.want)-succeeded {
+				t.Errorf("succeeded %d, failed %d, want %d and %d", response.Succeeded, response.Failed, succeeded, len(test.want)-succeeded)
+			}
+			if strings.Join(repo.inserted, ",") != strings.Join(test.inserted, ",") {
+				t.Errorf("inserted %v, want %v", repo.inserted, test.inserted)
+			}
+		})
+	}
+}
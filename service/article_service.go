@@ -1,23 +1,43 @@
 package service
 
 import (
+	"encoding/json"
+
 	"github.com/muhammadrijalkamal/backendtest/model"
 )
 
 type ArticleService interface {
-	Create(request *model.ArticleCreateRequest)
+	Create(request *model.ArticleCreateRequest) *model.ArticleResponse
+
+	CreateBulk(items []json.RawMessage, mode string) *model.BulkResponse
+
+	List(selection *model.Selection) *[]model.ArticleResponse
+
+	ListByTitle(title string, selection *model.Selection) *[]model.ArticleResponse
+
+	Stream(title string, selection *model.Selection, fn func(article *model.ArticleResponse) error) error
 
-	List() *[]model.ArticleResponse
+	ListPage(filter *model.ArticleFilter, selection *model.Selection) *[]model.ArticleResponse
 
-	ListByTitle(title string) *[]model.ArticleResponse
+	ListPageByCategories(categoryIDs []int64, filter *model.ArticleFilter, selection *model.Selection) map[int64][]model.ArticleResponse
 
-	ListSoftDeleted() *[]model.ArticleResponse
+	Count(filter *model.ArticleFilter) int64
 
-	FindOne(articleID string) *model.ArticleResponse
+	ListSoftDeleted(selection *model.Selection) *[]model.ArticleResponse
 
-	Update(articleID string, request *model.ArticleUpdateRequest)
+	FindOne(articleID string, selection *model.Selection) *model.ArticleResponse
+
+	FindOneBySlug(articleSlug string, selection *model.Selection) *model.ArticleResponse
+
+	Update(articleID string, request *model.ArticleUpdateRequest) *model.ArticleResponse
 
 	SoftDelete(articleID string)
 
 	Delete(articleID string)
+
+	SoftDeleteBulk(request *model.BulkDeleteRequest, mode string) *model.BulkResponse
+
+	DeleteBulk(request *model.BulkDeleteRequest, mode string) *model.BulkResponse
+
+	MoveToCategory(request *model.ArticleMoveRequest) *model.ArticleMoveResponse
 }
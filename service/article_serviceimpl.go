@@ -1,10 +1,13 @@
 package service
 
 import (
+	"encoding/json"
 	"strconv"
 
 	"github.com/gosimple/slug"
+	"github.com/muhammadrijalkamal/backendtest/content"
 	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/imaging"
 	"github.com/muhammadrijalkamal/backendtest/model"
 	"github.com/muhammadrijalkamal/backendtest/repository"
 	"github.com/muhammadrijalkamal/backendtest/util"
@@ -12,69 +15,184 @@ import (
 
 type ArticleServiceImpl struct {
 	articleRepository repository.ArticleRepository
+	imageSizes        []imaging.Size
 }
 
-func NewArticleService(repo *repository.ArticleRepository) ArticleService {
+func NewArticleService(repo *repository.ArticleRepository, imageSizes []imaging.Size) ArticleService {
 	return &ArticleServiceImpl{
 		articleRepository: *repo,
+		imageSizes:        imageSizes,
 	}
 }
 
-func (service *ArticleServiceImpl) Create(request *model.ArticleCreateRequest) {
+func (service *ArticleServiceImpl) Create(request *model.ArticleCreateRequest) *model.ArticleResponse {
 	articleSlug := slug.Make(request.Title)
 	article := entity.Article{
-		Title:      request.Title,
-		Slug:       articleSlug,
-		CategoryID: request.CategoryID,
-		Content:    request.Content,
+		Title:           request.Title,
+		Slug:            articleSlug,
+		CategoryID:      request.CategoryID,
+		Content:         request.Content,
+		Status:          articleStatus(request.Status),
+		FeaturedImageID: request.FeaturedImageID,
 	}
-	txErr := service.articleRepository.Insert(&article)
+	renderErr := renderArticleContent(&article, request.ContentFormat)
+	util.ReturnErrorIfNeeded(renderErr)
+
+	id, txErr := service.articleRepository.Insert(&article)
 	util.ReturnErrorIfNeeded(txErr)
+
+	created, txErr := service.articleRepository.FindByID(id, nil)
+	util.ReturnErrorIfNeeded(txErr)
+
+	service.describeImage(created)
+	return created
 }
 
-func (service *ArticleServiceImpl) List() *[]model.ArticleResponse {
-	articles, txErr := service.articleRepository.FindAll()
+func (service *ArticleServiceImpl) List(selection *model.Selection) *[]model.ArticleResponse {
+	articles, txErr := service.articleRepository.FindAll(selection)
 	util.ReturnErrorIfNeeded(txErr)
+	service.describeImages(articles)
 	return articles
 }
 
-func (service *ArticleServiceImpl) ListByTitle(title string) *[]model.ArticleResponse {
-	articles, txErr := service.articleRepository.FindAllByTitle(title)
+func (service *ArticleServiceImpl) ListByTitle(title string, selection *model.Selection) *[]model.ArticleResponse {
+	articles, txErr := service.articleRepository.FindAllByTitle(title, selection)
 	util.ReturnErrorIfNeeded(txErr)
+	service.describeImages(articles)
 	return articles
 }
 
-func (service *ArticleServiceImpl) ListSoftDeleted() *[]model.ArticleResponse {
-	articles, txErr := service.articleRepository.FindAllSoftDeleted()
+// Stream passes the articles of List, or ListByTitle, to fn as they are read.
+// It returns errors instead of panicking, since it runs while the response
+// is already being written.
+func (service *ArticleServiceImpl) Stream(title string, selection *model.Selection, fn func(article *model.ArticleResponse) error) error {
+	return service.articleRepository.ForEach(title, selection, func(article *model.ArticleResponse) error {
+		service.describeImage(article)
+		return fn(article)
+	})
+}
+
+func (service *ArticleServiceImpl) ListPage(filter *model.ArticleFilter, selection *model.Selection) *[]model.ArticleResponse {
+	validateArticleFilter(filter)
+
+	articles, txErr := service.articleRepository.FindPage(filter, selection)
+	util.ReturnErrorIfNeeded(txErr)
+	service.describeImages(articles)
+	return articles
+}
+
+// ListPageByCategories pages the articles of every category on its own, so
+// each gets up to filter.Limit of them.
+func (service *ArticleServiceImpl) ListPageByCategories(categoryIDs []int64, filter *model.ArticleFilter, selection *model.Selection) map[int64][]model.ArticleResponse {
+	validateArticleFilter(filter)
+
+	grouped, txErr := service.articleRepository.FindPageByCategories(categoryIDs, filter, selection)
+	util.ReturnErrorIfNeeded(txErr)
+	for _, articles := range grouped {
+		service.describeImages(&articles)
+	}
+	return grouped
+}
+
+func (service *ArticleServiceImpl) Count(filter *model.ArticleFilter) int64 {
+	validateArticleFilter(filter)
+
+	count, txErr := service.articleRepository.Count(filter)
+	util.ReturnErrorIfNeeded(txErr)
+	return count
+}
+
+func (service *ArticleServiceImpl) ListSoftDeleted(selection *model.Selection) *[]model.ArticleResponse {
+	articles, txErr := service.articleRepository.FindAllSoftDeleted(selection)
 	util.ReturnErrorIfNeeded(txErr)
+	service.describeImages(articles)
 	return articles
 }
 
-func (service *ArticleServiceImpl) FindOne(articleID string) *model.ArticleResponse {
+func (service *ArticleServiceImpl) FindOne(articleID string, selection *model.Selection) *model.ArticleResponse {
 	id, err := strconv.Atoi(articleID)
 	util.ReturnErrorIfNeeded(err)
 
-	article, txErr := service.articleRepository.FindByID(int64(id))
+	article, txErr := service.articleRepository.FindByID(int64(id), selection)
 	util.ReturnErrorIfNeeded(txErr)
 
+	service.describeImage(article)
 	return article
 }
 
-func (service *ArticleServiceImpl) Update(articleID string, request *model.ArticleUpdateRequest) {
+// FindOneBySlug returns nil for unknown slugs. The ID is always set, even
+// when the selection leaves it out, so callers can key caches on it.
+func (service *ArticleServiceImpl) FindOneBySlug(articleSlug string, selection *model.Selection) *model.ArticleResponse {
+	ids, txErr := service.articleRepository.FindIDsBySlugs([]string{articleSlug})
+	util.ReturnErrorIfNeeded(txErr)
+
+	id, ok := ids[articleSlug]
+	if !ok {
+		return nil
+	}
+
+	article, txErr := service.articleRepository.FindByID(id, selection)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if article == nil {
+		return nil
+	}
+
+	article.ID = id
+	service.describeImage(article)
+	return article
+}
+
+func (service *ArticleServiceImpl) Update(articleID string, request *model.ArticleUpdateRequest) *model.ArticleResponse {
 	id, err := strconv.Atoi(articleID)
 	util.ReturnErrorIfNeeded(err)
 
 	articleSlug := slug.Make(request.Title)
 
+	// Without a status the article keeps its own; only Create defaults to
+	// published.
+	status := ""
+	if request.Status != "" {
+		status = articleStatus(request.Status)
+	}
+
+	// The content format and the featured image are kept as well when the
+	// request leaves them out.
+	contentFormat, featuredImageID := request.ContentFormat, request.FeaturedImageID
+	if contentFormat == "" || featuredImageID == 0 {
+		stored, txErr := service.articleRepository.FindByID(int64(id), &model.Selection{Fields: []string{"content_format", "featured_image_id"}})
+		util.ReturnErrorIfNeeded(txErr)
+
+		if stored == nil {
+			panic(util.NotFoundError("article not found"))
+		}
+		if contentFormat == "" {
+			contentFormat = stored.ContentFormat
+		}
+		if featuredImageID == 0 {
+			featuredImageID = stored.FeaturedImageID
+		}
+	}
+
 	article := entity.Article{
-		Title:      request.Title,
-		Slug:       articleSlug,
-		CategoryID: request.CategoryID,
-		Content:    request.Content,
+		Title:           request.Title,
+		Slug:            articleSlug,
+		CategoryID:      request.CategoryID,
+		Content:         request.Content,
+		Status:          status,
+		FeaturedImageID: featuredImageID,
 	}
+	renderErr := renderArticleContent(&article, contentFormat)
+	util.ReturnErrorIfNeeded(renderErr)
 
 	txErr := service.articleRepository.Update(int64(id), &article)
 	util.ReturnErrorIfNeeded(txErr)
+
+	updated, txErr := service.articleRepository.FindByID(int64(id), nil)
+	util.ReturnErrorIfNeeded(txErr)
+
+	service.describeImage(updated)
+	return updated
 }
 
 func (service *ArticleServiceImpl) SoftDelete(articleID string) {
@@ -92,3 +210,86 @@ func (service *ArticleServiceImpl) Delete(articleID string) {
 	txErr := service.articleRepository.Delete(int64(id))
 	util.ReturnErrorIfNeeded(txErr)
 }
+
+func (service *ArticleServiceImpl) MoveToCategory(request *model.ArticleMoveRequest) *model.ArticleMoveResponse {
+	if len(request.ArticleIDs) == 0 {
+		panic(util.InvalidError("article_ids must not be empty"))
+	}
+
+	moved, txErr := service.articleRepository.MoveToCategory(request.ArticleIDs, request.CategoryID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return &model.ArticleMoveResponse{
+		CategoryID: request.CategoryID,
+		Moved:      moved,
+	}
+}
+
+func (service *ArticleServiceImpl) describeImages(articles *[]model.ArticleResponse) {
+	if articles == nil {
+		return
+	}
+
+	for i := range *articles {
+		service.describeImage(&(*articles)[i])
+	}
+}
+
+func (service *ArticleServiceImpl) describeImage(article *model.ArticleResponse) {
+	if article != nil && article.FeaturedImage != nil {
+		imaging.Describe(article.FeaturedImage, service.imageSizes)
+	}
+}
+
+func articleStatus(status string) string {
+	validStatus, err := validateArticleStatus(status)
+	util.ReturnErrorIfNeeded(err)
+	return validStatus
+}
+
+func renderArticleContent(article *entity.Article, format string) error {
+	validFormat, err := content.ValidateFormat(format)
+	if err != nil {
+		return util.InvalidError(err.Error())
+	}
+
+	rendered, err := content.Render(validFormat, article.Content)
+	if err != nil {
+		return err
+	}
+
+	toc, err := json.Marshal(rendered.TOC)
+	if err != nil {
+		return err
+	}
+
+	article.ContentFormat = validFormat
+	article.ContentHTML = rendered.HTML
+	article.ContentText = rendered.Text
+	article.TOC = string(toc)
+	article.Excerpt = rendered.Excerpt
+	article.WordCount = rendered.WordCount
+	article.ReadingTime = rendered.ReadingTimeMinutes
+	return nil
+}
+
+func validateArticleFilter(filter *model.ArticleFilter) {
+	if filter.Status != "" {
+		articleStatus(filter.Status)
+	}
+
+	if filter.Limit < 0 || filter.Offset < 0 {
+		panic(util.InvalidError("limit and offset cannot be negative"))
+	}
+}
+
+func validateArticleStatus(status string) (string, error) {
+	switch status {
+	case "":
+		return entity.ArticleStatusPublished, nil
+	case entity.ArticleStatusDraft, entity.ArticleStatusPublished:
+		return status, nil
+	default:
+		return "", util.InvalidError("invalid article status")
+	}
+}
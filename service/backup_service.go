@@ -0,0 +1,13 @@
+package service
+
+import (
+	"io"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type BackupService interface {
+	Export(w io.Writer) error
+
+	Restore(r io.ReaderAt, size int64) (*model.RestoreReport, error)
+}
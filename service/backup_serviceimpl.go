@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/backup"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+)
+
+type BackupServiceImpl struct {
+	backupRepository repository.BackupRepository
+	blobStore        storage.BlobStore
+}
+
+func NewBackupService(backupRepo *repository.BackupRepository, blobStore storage.BlobStore) BackupService {
+	return &BackupServiceImpl{
+		backupRepository: *backupRepo,
+		blobStore:        blobStore,
+	}
+}
+
+// Export writes every table as a JSONL file and then the original of every
+// media item. It returns errors instead of panicking since it runs while
+// the response is being streamed.
+func (service *BackupServiceImpl) Export(w io.Writer) error {
+	archive := backup.NewWriter(w, time.Now())
+
+	var blobs []string
+	err := service.backupRepository.Export(func(table string, record interface{}) error {
+		if media, ok := record.(*model.ExportMedia); ok {
+			blobs = append(blobs, media.SHA256)
+		}
+		return archive.Encode(table, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Tables without rows still get a file.
+	for _, table := range model.ExportTables {
+		if err := archive.Table(table); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range blobs {
+		if err := service.exportBlob(archive, key); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (service *BackupServiceImpl) exportBlob(archive *backup.Writer, key string) error {
+	content, err := service.blobStore.Open(key)
+	if err != nil {
+		return errors.New("media " + key + ": " + err.Error())
+	}
+	defer content.Close()
+
+	return archive.Blob(key, content)
+}
+
+// Restore verifies the archive before writing anything. Blobs are stored
+// first; the rows are inserted in one transaction that is only committed
+// when every table was restored.
+func (service *BackupServiceImpl) Restore(r io.ReaderAt, size int64) (*model.RestoreReport, error) {
+	archive, err := backup.OpenReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, table := range model.ExportTables {
+		if archive.Has(backup.TableFile(table)) {
+			tables = append(tables, table)
+			continue
+		}
+
+		// Archives written before a table was exported restore without it.
+		if archive.Manifest.Version < model.ExportTableSince[table] {
+			continue
+		}
+		return nil, errors.New("the archive has no " + backup.TableFile(table))
+	}
+
+	report := &model.RestoreReport{Version: archive.Manifest.Version, Records: map[string]int{}}
+
+	tx, err := service.backupRepository.BeginRestore()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		err := archive.Records(table, func() interface{} { return model.NewExportRecord(table) }, func(record interface{}) error {
+			if media, ok := record.(*model.ExportMedia); ok {
+				if err := service.restoreBlob(archive, media.SHA256); err != nil {
+					return err
+				}
+				report.Blobs++
+			}
+
+			report.Records[table]++
+			return tx.Insert(table, record)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (service *BackupServiceImpl) restoreBlob(archive *backup.Reader, key string) error {
+	content, err := archive.Open(backup.BlobFile(key))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	return service.blobStore.Put(key, content)
+}
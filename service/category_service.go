@@ -5,17 +5,29 @@ import (
 )
 
 type CategoryService interface {
-	Create(request *model.CategoryCreateRequest)
+	Create(request *model.CategoryCreateRequest) *model.CategoryResponse
 
 	List() *[]model.CategoryResponse
 
+	ListWithStats() *[]model.CategoryResponse
+
+	Stream(withStats bool, fn func(category *model.CategoryResponse) error) error
+
 	ListSoftDeleted() *[]model.CategoryResponse
 
 	FindOne(categoryID string) *model.CategoryResponse
 
-	Update(categoryID string, request *model.CategoryUpdateRequest)
+	FindOneWithStats(categoryID string) *model.CategoryResponse
+
+	FindAllByIDs(categoryIDs []int64, withStats bool) *[]model.CategoryResponse
+
+	FindOneBySlug(categorySlug string) *model.CategoryResponse
+
+	Update(categoryID string, request *model.CategoryUpdateRequest) *model.CategoryResponse
 
 	SoftDelete(categoryID string)
 
 	Delete(categoryID string)
+
+	Merge(categoryID string, request *model.CategoryMergeRequest) *model.CategoryResponse
 }
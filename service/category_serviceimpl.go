@@ -20,14 +20,19 @@ func NewCategoryService(repo *repository.CategoryRepository) CategoryService {
 	}
 }
 
-func (service *CategoryServiceImpl) Create(request *model.CategoryCreateRequest) {
+func (service *CategoryServiceImpl) Create(request *model.CategoryCreateRequest) *model.CategoryResponse {
 	categorySlug := slug.Make(request.CategoryName)
 	article := entity.Category{
 		CategoryName: request.CategoryName,
 		CategorySlug: categorySlug,
 	}
-	txErr := service.categoryRepository.Insert(&article)
+	id, txErr := service.categoryRepository.Insert(&article)
 	util.ReturnErrorIfNeeded(txErr)
+
+	created, txErr := service.categoryRepository.FindByID(id)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return created
 }
 
 func (service *CategoryServiceImpl) List() *[]model.CategoryResponse {
@@ -36,6 +41,18 @@ func (service *CategoryServiceImpl) List() *[]model.CategoryResponse {
 	return categories
 }
 
+func (service *CategoryServiceImpl) ListWithStats() *[]model.CategoryResponse {
+	categories, txErr := service.categoryRepository.FindAllWithStats()
+	util.ReturnErrorIfNeeded(txErr)
+	return categories
+}
+
+// Stream passes the categories of List, or ListWithStats, to fn as they are
+// read and returns errors instead of panicking.
+func (service *CategoryServiceImpl) Stream(withStats bool, fn func(category *model.CategoryResponse) error) error {
+	return service.categoryRepository.ForEach(withStats, fn)
+}
+
 func (service *CategoryServiceImpl) ListSoftDeleted() *[]model.CategoryResponse {
 	categories, txErr := service.categoryRepository.FindAllSoftDeleted()
 	util.ReturnErrorIfNeeded(txErr)
@@ -52,7 +69,46 @@ func (service *CategoryServiceImpl) FindOne(categoryID string) *model.CategoryRe
 	return category
 }
 
-func (service *CategoryServiceImpl) Update(categoryID string, request *model.CategoryUpdateRequest) {
+func (service *CategoryServiceImpl) FindOneWithStats(categoryID string) *model.CategoryResponse {
+	id, err := strconv.Atoi(categoryID)
+	util.ReturnErrorIfNeeded(err)
+
+	category, txErr := service.categoryRepository.FindByIDWithStats(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return category
+}
+
+func (service *CategoryServiceImpl) FindAllByIDs(categoryIDs []int64, withStats bool) *[]model.CategoryResponse {
+	categories, txErr := service.categoryRepository.FindAllByIDs(categoryIDs, withStats)
+	util.ReturnErrorIfNeeded(txErr)
+	return categories
+}
+
+// FindOneBySlug resolves slugs of merged categories to their merge target, so
+// callers can tell a redirect apart by comparing the returned slug.
+func (service *CategoryServiceImpl) FindOneBySlug(categorySlug string) *model.CategoryResponse {
+	category, txErr := service.categoryRepository.FindBySlug(categorySlug)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if category != nil {
+		return category
+	}
+
+	targetID, txErr := service.categoryRepository.FindRedirect(categorySlug)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if targetID == 0 {
+		return nil
+	}
+
+	category, txErr = service.categoryRepository.FindByID(targetID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return category
+}
+
+func (service *CategoryServiceImpl) Update(categoryID string, request *model.CategoryUpdateRequest) *model.CategoryResponse {
 	id, err := strconv.Atoi(categoryID)
 	util.ReturnErrorIfNeeded(err)
 
@@ -65,6 +121,11 @@ func (service *CategoryServiceImpl) Update(categoryID string, request *model.Cat
 
 	txErr := service.categoryRepository.Update(int64(id), &category)
 	util.ReturnErrorIfNeeded(txErr)
+
+	updated, txErr := service.categoryRepository.FindByID(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return updated
 }
 
 func (service *CategoryServiceImpl) SoftDelete(categoryID string) {
@@ -82,3 +143,20 @@ func (service *CategoryServiceImpl) Delete(categoryID string) {
 	txErr := service.categoryRepository.Delete(int64(id))
 	util.ReturnErrorIfNeeded(txErr)
 }
+
+func (service *CategoryServiceImpl) Merge(categoryID string, request *model.CategoryMergeRequest) *model.CategoryResponse {
+	id, err := strconv.Atoi(categoryID)
+	util.ReturnErrorIfNeeded(err)
+
+	if int64(id) == request.TargetID {
+		panic(util.InvalidError("cannot merge a category into itself"))
+	}
+
+	txErr := service.categoryRepository.Merge(int64(id), request.TargetID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	category, txErr := service.categoryRepository.FindByID(request.TargetID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return category
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+// ChangeListener is notified by the outbox relay after an article or
+// category write has been committed, e.g. to drop derived data such as
+// generated sitemaps.
+type ChangeListener interface {
+	OnChange(event model.ChangeEvent)
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type CommentService interface {
+	Create(articleID string, request *model.CommentCreateRequest) *model.CommentResponse
+
+	ListByArticle(articleID string) *[]model.CommentResponse
+
+	ListForModeration(status string) *[]model.CommentResponse
+
+	ListSoftDeleted() *[]model.CommentResponse
+
+	Moderate(commentID string, request *model.CommentModerateRequest) *model.CommentResponse
+
+	SoftDelete(commentID string)
+
+	Delete(commentID string)
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const (
+	maxCommentAuthorLength = 100
+	maxCommentBodyLength   = 5000
+	moderationQueueLimit   = 200
+)
+
+type CommentServiceImpl struct {
+	commentRepository repository.CommentRepository
+	articleRepository repository.ArticleRepository
+}
+
+func NewCommentService(commentRepo *repository.CommentRepository, articleRepo *repository.ArticleRepository) CommentService {
+	return &CommentServiceImpl{
+		commentRepository: *commentRepo,
+		articleRepository: *articleRepo,
+	}
+}
+
+// Create returns nil when the article does not accept comments. New comments
+// wait in the moderation queue until an editor approves them.
+func (service *CommentServiceImpl) Create(articleID string, request *model.CommentCreateRequest) *model.CommentResponse {
+	article := service.findCommentableArticle(articleID)
+	if article == nil {
+		return nil
+	}
+
+	comment := entity.Comment{
+		ArticleID:   article.ID,
+		ParentID:    request.ParentID,
+		AuthorName:  strings.TrimSpace(request.AuthorName),
+		AuthorEmail: strings.TrimSpace(request.AuthorEmail),
+		Body:        strings.TrimSpace(request.Body),
+		Status:      entity.CommentStatusPending,
+	}
+	util.ReturnErrorIfNeeded(validateComment(&comment))
+
+	if comment.ParentID != 0 {
+		parent, txErr := service.commentRepository.FindByID(comment.ParentID)
+		util.ReturnErrorIfNeeded(txErr)
+
+		if parent == nil || parent.ArticleID != comment.ArticleID || !parent.DeletedAt.IsZero() {
+			panic(errors.New("parent comment not found on this article"))
+		}
+	}
+
+	id, txErr := service.commentRepository.Insert(&comment)
+	util.ReturnErrorIfNeeded(txErr)
+
+	created, txErr := service.commentRepository.FindByID(id)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return created
+}
+
+// ListByArticle returns the approved comments of an article as a thread.
+// Deleted comments that still have replies are kept as empty placeholders.
+func (service *CommentServiceImpl) ListByArticle(articleID string) *[]model.CommentResponse {
+	article := service.findCommentableArticle(articleID)
+	if article == nil {
+		return nil
+	}
+
+	comments, txErr := service.commentRepository.FindAllByArticle(article.ID, entity.CommentStatusApproved)
+	util.ReturnErrorIfNeeded(txErr)
+
+	thread := buildCommentThread(*comments)
+	return &thread
+}
+
+func (service *CommentServiceImpl) ListForModeration(status string) *[]model.CommentResponse {
+	if status == "" {
+		status = entity.CommentStatusPending
+	}
+
+	if !validCommentStatus(status) {
+		panic(errors.New("unknown comment status " + strconv.Quote(status)))
+	}
+
+	comments, txErr := service.commentRepository.FindAllByStatus(status, moderationQueueLimit)
+	util.ReturnErrorIfNeeded(txErr)
+	return comments
+}
+
+func (service *CommentServiceImpl) ListSoftDeleted() *[]model.CommentResponse {
+	comments, txErr := service.commentRepository.FindAllSoftDeleted()
+	util.ReturnErrorIfNeeded(txErr)
+	return comments
+}
+
+func (service *CommentServiceImpl) Moderate(commentID string, request *model.CommentModerateRequest) *model.CommentResponse {
+	id, err := strconv.Atoi(commentID)
+	util.ReturnErrorIfNeeded(err)
+
+	if !validCommentStatus(request.Status) {
+		panic(errors.New("unknown comment status " + strconv.Quote(request.Status)))
+	}
+
+	comment, txErr := service.commentRepository.FindByID(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	if comment == nil || !comment.DeletedAt.IsZero() {
+		return nil
+	}
+
+	txErr = service.commentRepository.UpdateStatus(int64(id), request.Status)
+	util.ReturnErrorIfNeeded(txErr)
+
+	updated, txErr := service.commentRepository.FindByID(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return updated
+}
+
+func (service *CommentServiceImpl) SoftDelete(commentID string) {
+	id, err := strconv.Atoi(commentID)
+	util.ReturnErrorIfNeeded(err)
+
+	txErr := service.commentRepository.SoftDelete(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+}
+
+func (service *CommentServiceImpl) Delete(commentID string) {
+	id, err := strconv.Atoi(commentID)
+	util.ReturnErrorIfNeeded(err)
+
+	txErr := service.commentRepository.Delete(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+}
+
+func (service *CommentServiceImpl) findCommentableArticle(articleID string) *model.ArticleResponse {
+	id, err := strconv.Atoi(articleID)
+	util.ReturnErrorIfNeeded(err)
+
+	article, txErr := service.articleRepository.FindByID(int64(id), nil)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if article == nil || !article.DeletedAt.IsZero() || article.Status != entity.ArticleStatusPublished {
+		return nil
+	}
+
+	return article
+}
+
+func validateComment(comment *entity.Comment) error {
+	if comment.AuthorName == "" {
+		return errors.New("author_name must not be empty")
+	}
+
+	if utf8.RuneCountInString(comment.AuthorName) > maxCommentAuthorLength {
+		return errors.New("author_name must be at most " + strconv.Itoa(maxCommentAuthorLength) + " characters")
+	}
+
+	if comment.AuthorEmail != "" {
+		if _, err := mail.ParseAddress(comment.AuthorEmail); err != nil {
+			return errors.New("author_email is not a valid address")
+		}
+	}
+
+	if comment.Body == "" {
+		return errors.New("body must not be empty")
+	}
+
+	if utf8.RuneCountInString(comment.Body) > maxCommentBodyLength {
+		return errors.New("body must be at most " + strconv.Itoa(maxCommentBodyLength) + " characters")
+	}
+
+	return nil
+}
+
+func validCommentStatus(status string) bool {
+	switch status {
+	case entity.CommentStatusPending, entity.CommentStatusApproved, entity.CommentStatusRejected, entity.CommentStatusSpam:
+		return true
+	}
+	return false
+}
+
+// buildCommentThread nests replies under their parents. Replies whose parent
+// is not visible are promoted to the top level, and author emails are never
+// part of the public thread.
+func buildCommentThread(comments []model.CommentResponse) []model.CommentResponse {
+	visible := map[int64]bool{}
+	children := map[int64][]int{}
+	var roots []int
+	for i := range comments {
+		comments[i].AuthorEmail = ""
+		visible[comments[i].ID] = true
+	}
+
+	for i, comment := range comments {
+		if comment.ParentID != 0 && visible[comment.ParentID] {
+			children[comment.ParentID] = append(children[comment.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var build func(indexes []int) []model.CommentResponse
+	build = func(indexes []int) []model.CommentResponse {
+		thread := make([]model.CommentResponse, 0, len(indexes))
+		for _, i := range indexes {
+			comment := comments[i]
+			comment.Replies = build(children[comment.ID])
+			if !comment.DeletedAt.IsZero() {
+				if len(comment.Replies) == 0 {
+					continue
+				}
+				comment.AuthorName = ""
+				comment.Body = ""
+			}
+			thread = append(thread, comment)
+		}
+		return thread
+	}
+
+	return build(roots)
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type EventStreamService interface {
+	ChangeListener
+
+	Subscribe(lastEventID string) *EventSubscription
+}
+
+// EventSubscription delivers live events on Events, after Replay has been
+// sent. Reset is set when the requested Last-Event-ID is no longer buffered
+// and the client has to reload its state. Events is closed if the
+// subscriber falls too far behind.
+type EventSubscription struct {
+	Replay []model.ChangeEvent
+	Reset  bool
+	Events <-chan model.ChangeEvent
+	Cancel func()
+}
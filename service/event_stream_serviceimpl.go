@@ -0,0 +1,82 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+const (
+	eventStreamReplaySize      = 1000
+	eventStreamSubscriberQueue = 256
+)
+
+type EventStreamServiceImpl struct {
+	mutex       sync.Mutex
+	replay      []model.ChangeEvent
+	subscribers map[chan model.ChangeEvent]bool
+}
+
+func NewEventStreamService() EventStreamService {
+	return &EventStreamServiceImpl{
+		subscribers: map[chan model.ChangeEvent]bool{},
+	}
+}
+
+func (service *EventStreamServiceImpl) OnChange(event model.ChangeEvent) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	service.replay = append(service.replay, event)
+	if len(service.replay) > eventStreamReplaySize {
+		service.replay = service.replay[len(service.replay)-eventStreamReplaySize:]
+	}
+
+	for events := range service.subscribers {
+		select {
+		case events <- event:
+		default:
+			// A subscriber that can't keep up is dropped; it reconnects with
+			// Last-Event-ID and catches up from the replay buffer.
+			delete(service.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Subscribe replays the buffered events that followed lastEventID. Events
+// are buffered in the order they were published, which is not always
+// ascending by ID, so the position of lastEventID is looked up rather than
+// compared.
+func (service *EventStreamServiceImpl) Subscribe(lastEventID string) *EventSubscription {
+	events := make(chan model.ChangeEvent, eventStreamSubscriberQueue)
+	subscription := &EventSubscription{Events: events}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if sequence, err := strconv.ParseInt(lastEventID, 10, 64); err == nil {
+		subscription.Reset = true
+		for i, event := range service.replay {
+			if event.Sequence == sequence {
+				subscription.Replay = append(subscription.Replay, service.replay[i+1:]...)
+				subscription.Reset = false
+				break
+			}
+		}
+	}
+
+	service.subscribers[events] = true
+	subscription.Cancel = func() {
+		service.mutex.Lock()
+		defer service.mutex.Unlock()
+
+		if service.subscribers[events] {
+			delete(service.subscribers, events)
+			close(events)
+		}
+	}
+
+	return subscription
+}
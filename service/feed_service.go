@@ -0,0 +1,9 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type FeedService interface {
+	Source(category *model.CategoryResponse) *model.FeedSource
+}
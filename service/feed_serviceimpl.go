@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/feed"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const feedSize = 50
+
+var feedSelection = model.Selection{
+	Fields: []string{
+		"id", "title", "slug", "category_id", "category_name", "category_slug",
+		"content_html", "excerpt", "published_at", "created_at", "updated_at",
+	},
+}
+
+type FeedServiceImpl struct {
+	articleRepository repository.ArticleRepository
+	title             string
+}
+
+func NewFeedService(repo *repository.ArticleRepository, title string) FeedService {
+	return &FeedServiceImpl{
+		articleRepository: *repo,
+		title:             title,
+	}
+}
+
+func (service *FeedServiceImpl) Source(category *model.CategoryResponse) *model.FeedSource {
+	source := model.FeedSource{
+		Title:    service.title,
+		Category: category,
+	}
+
+	var categoryID int64
+	if category != nil {
+		categoryID = category.ID
+		source.Title = service.title + " - " + category.CategoryName
+	}
+
+	articles, txErr := service.articleRepository.FindAllPublished(categoryID, feedSize, &feedSelection)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if articles != nil {
+		source.Articles = *articles
+	}
+
+	for i := range source.Articles {
+		if updated := feed.Updated(&source.Articles[i]); updated.After(source.Updated) {
+			source.Updated = updated
+		}
+	}
+
+	return &source
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/importer"
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type ImportService interface {
+	ImportMarkdown(files []importer.SourceFile, dryRun bool) *model.ImportReport
+
+	ImportWXR(export *importer.WXR, dryRun bool) *model.ImportReport
+}
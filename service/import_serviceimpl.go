@@ -0,0 +1,497 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosimple/slug"
+	"github.com/muhammadrijalkamal/backendtest/content"
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/importer"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const (
+	maxArticleTitleLength  = 100
+	maxCategoryNameLength  = 30
+	importReasonSlugExists = "an article with this slug already exists"
+	importReasonDuplicate  = "the slug is used by an earlier file of this import"
+	importReasonImported   = "the post was imported by an earlier run"
+	importReasonAdopted    = "an article with this slug and title already exists and is now linked to the post"
+	wxrDefaultCategory     = "Uncategorized"
+)
+
+type ImportServiceImpl struct {
+	articleRepository repository.ArticleRepository
+	importRepository  repository.ImportRepository
+	categoryService   CategoryService
+}
+
+func NewImportService(articleRepo *repository.ArticleRepository, importRepo *repository.ImportRepository, categoryService *CategoryService) ImportService {
+	return &ImportServiceImpl{
+		articleRepository: *articleRepo,
+		importRepository:  *importRepo,
+		categoryService:   *categoryService,
+	}
+}
+
+// ImportMarkdown creates an article for every post whose slug is not taken
+// yet, so running it again only picks up what is new. Missing categories are
+// created through the category service. In a dry run nothing is written and
+// the report lists what would happen.
+func (service *ImportServiceImpl) ImportMarkdown(files []importer.SourceFile, dryRun bool) *model.ImportReport {
+	report := &model.ImportReport{DryRun: dryRun, CategoriesCreated: []string{}, Items: []model.ImportItemResult{}}
+
+	posts := make([]*importer.MarkdownPost, len(files))
+	parseErrors := make([]error, len(files))
+	var slugs []string
+	for i, file := range files {
+		post, err := importer.ParseMarkdown(file)
+		if err != nil {
+			parseErrors[i] = err
+			continue
+		}
+
+		if !slug.IsSlug(post.Slug) {
+			post.Slug = slug.Make(post.Slug)
+		}
+
+		posts[i] = post
+		slugs = append(slugs, post.Slug)
+	}
+
+	existing, txErr := service.articleRepository.FindIDsBySlugs(slugs)
+	util.ReturnErrorIfNeeded(txErr)
+
+	categories := service.categoryIndex()
+	seen := map[string]bool{}
+	for i, post := range posts {
+		if parseErrors[i] != nil {
+			report.Failed++
+			report.Items = append(report.Items, model.ImportItemResult{Source: files[i].Path, Action: model.ImportActionFail, Error: parseErrors[i].Error()})
+			continue
+		}
+
+		item := model.ImportItemResult{
+			Source:   post.Path,
+			Slug:     post.Slug,
+			Title:    post.Title,
+			Category: post.Category,
+			Tags:     post.Tags,
+		}
+
+		switch {
+		case existing[post.Slug] != 0:
+			item.Action = model.ImportActionSkip
+			item.ID = existing[post.Slug]
+			item.Reason = importReasonSlugExists
+		case seen[post.Slug]:
+			item.Action = model.ImportActionSkip
+			item.Reason = importReasonDuplicate
+		default:
+			id, err := service.importPost(post, categories, report, dryRun)
+			if err != nil {
+				item.Action = model.ImportActionFail
+				item.Error = err.Error()
+			} else {
+				item.Action = model.ImportActionCreate
+				item.ID = id
+			}
+		}
+
+		seen[post.Slug] = true
+		switch item.Action {
+		case model.ImportActionCreate:
+			report.Created++
+		case model.ImportActionSkip:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	return report
+}
+
+// ImportWXR imports the posts of a WordPress export. Every category and post
+// that is written is recorded in the import mappings under the site URL, so
+// a run that failed halfway can simply be repeated: mapped posts are skipped
+// and only the rest is created. Links between posts and to category archives
+// are rewritten to the slug routes of the imported articles and categories.
+func (service *ImportServiceImpl) ImportWXR(export *importer.WXR, dryRun bool) *model.ImportReport {
+	report := &model.ImportReport{DryRun: dryRun, CategoriesCreated: []string{}, Items: []model.ImportItemResult{}}
+
+	source := importer.NormalizeURL(export.SiteURL)
+	if source == "" {
+		panic(errors.New("the export has no valid site URL"))
+	}
+
+	categoryMappings, txErr := service.importRepository.FindMappings(source, repository.ImportKindCategory)
+	util.ReturnErrorIfNeeded(txErr)
+
+	postMappings, txErr := service.importRepository.FindMappings(source, repository.ImportKindPost)
+	util.ReturnErrorIfNeeded(txErr)
+
+	links := map[string]string{}
+	categories := service.importWXRCategories(export, source, categoryMappings, links, report, dryRun)
+
+	slugs, adopted := service.planWXRSlugs(export.Posts, postMappings)
+	for i, post := range export.Posts {
+		if slugs[i] != "" {
+			for _, link := range importer.PostURLs(export.SiteURL, post) {
+				links[importer.NormalizeURL(link)] = "/article/slug/" + slugs[i]
+			}
+		} else if id := postMappings[strconv.FormatInt(post.ID, 10)]; id != 0 {
+			for _, link := range importer.PostURLs(export.SiteURL, post) {
+				links[importer.NormalizeURL(link)] = "/article/" + strconv.FormatInt(id, 10)
+			}
+		}
+	}
+
+	for i, post := range export.Posts {
+		item := model.ImportItemResult{
+			Source: "post " + strconv.FormatInt(post.ID, 10),
+			Slug:   slugs[i],
+			Title:  post.Title,
+			Tags:   post.Tags,
+		}
+		if len(post.Categories) > 0 {
+			item.Category = post.Categories[0].Name
+		}
+
+		externalID := strconv.FormatInt(post.ID, 10)
+		status, trashed, statusErr := wxrStatus(post.Status)
+		switch {
+		case postMappings[externalID] != 0:
+			item.Action = model.ImportActionSkip
+			item.ID = postMappings[externalID]
+			item.Reason = importReasonImported
+		case statusErr != nil:
+			item.Action = model.ImportActionSkip
+			item.Reason = statusErr.Error()
+		case adopted[i] != 0:
+			item.Action = model.ImportActionSkip
+			item.ID = adopted[i]
+			item.Reason = importReasonAdopted
+			if !dryRun {
+				util.ReturnErrorIfNeeded(service.importRepository.SaveMapping(source, repository.ImportKindPost, externalID, adopted[i]))
+			}
+		default:
+			article := entity.Article{
+				Title:       post.Title,
+				Slug:        slugs[i],
+				Content:     importer.RewriteLinks(importer.Autop(post.Content), export.SiteURL, links),
+				Status:      status,
+				CreatedAt:   post.Date,
+				PublishedAt: post.Date,
+			}
+			id, err := service.importWXRPost(&article, post, categories, source, trashed, report, dryRun)
+			if err != nil {
+				item.Action = model.ImportActionFail
+				item.Error = err.Error()
+			} else {
+				item.Action = model.ImportActionCreate
+				item.ID = id
+			}
+		}
+
+		switch item.Action {
+		case model.ImportActionCreate:
+			report.Created++
+		case model.ImportActionSkip:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	return report
+}
+
+// importWXRCategories resolves every WordPress category, whether listed in
+// the channel or only on a post, to a local category and returns them by
+// nicename.
+func (service *ImportServiceImpl) importWXRCategories(export *importer.WXR, source string, mappings map[string]int64, links map[string]string, report *model.ImportReport, dryRun bool) map[string]int64 {
+	wpCategories := append([]importer.WXRCategory{}, export.Categories...)
+	for _, post := range export.Posts {
+		for _, term := range post.Categories {
+			wpCategories = append(wpCategories, importer.WXRCategory{Nicename: term.Nicename, Name: term.Name})
+		}
+	}
+
+	slugs := map[int64]string{}
+	if existing := service.categoryService.List(); existing != nil {
+		for _, category := range *existing {
+			slugs[category.ID] = category.CategorySlug
+		}
+	}
+
+	index := service.categoryIndex()
+	resolved := map[string]int64{}
+	for _, wpCategory := range wpCategories {
+		if _, ok := resolved[wpCategory.Nicename]; ok || wpCategory.Nicename == "" {
+			continue
+		}
+
+		id, mapped := mappings[wpCategory.Nicename]
+		if _, exists := slugs[id]; !mapped || !exists {
+			var err error
+			if id, mapped = index[wpCategory.Nicename]; !mapped {
+				id, err = service.resolveCategory(wpCategory.Name, index, report, dryRun)
+			}
+			if err != nil {
+				continue
+			}
+
+			if !dryRun {
+				util.ReturnErrorIfNeeded(service.importRepository.SaveMapping(source, repository.ImportKindCategory, wpCategory.Nicename, id))
+			}
+		}
+
+		resolved[wpCategory.Nicename] = id
+		categorySlug, ok := slugs[id]
+		if !ok {
+			categorySlug = slug.Make(wpCategory.Name)
+		}
+		for _, link := range importer.CategoryURLs(export.SiteURL, wpCategory) {
+			links[importer.NormalizeURL(link)] = "/category/slug/" + categorySlug
+		}
+	}
+
+	return resolved
+}
+
+// planWXRSlugs picks the slug of every post: its WordPress slug, or that slug
+// suffixed with the post ID when another article already uses it. Imported
+// posts keep the slug they were given, as long as it has not changed since.
+// An unmapped article with a candidate slug and the post's title is one that
+// an earlier run created but could not record; its ID is returned in adopted.
+func (service *ImportServiceImpl) planWXRSlugs(posts []importer.WXRPost, mappings map[string]int64) (slugs []string, adopted []int64) {
+	candidates := make([][]string, len(posts))
+	var all []string
+	for i, post := range posts {
+		base := post.Name
+		if !slug.IsSlug(base) {
+			base = slug.Make(base)
+		}
+		if base == "" {
+			base = slug.Make(post.Title)
+		}
+		if base == "" {
+			continue
+		}
+
+		candidates[i] = []string{base, base + "-" + strconv.FormatInt(post.ID, 10)}
+		all = append(all, candidates[i]...)
+	}
+
+	existing, txErr := service.articleRepository.FindIDsBySlugs(all)
+	util.ReturnErrorIfNeeded(txErr)
+
+	owners := map[int64]bool{}
+	for _, id := range mappings {
+		owners[id] = true
+	}
+
+	slugs = make([]string, len(posts))
+	adopted = make([]int64, len(posts))
+	claimed := map[string]bool{}
+	for i, post := range posts {
+		mapped := mappings[strconv.FormatInt(post.ID, 10)]
+		for _, candidate := range candidates[i] {
+			id := existing[candidate]
+			if mapped != 0 {
+				if id == mapped {
+					slugs[i] = candidate
+					break
+				}
+				continue
+			}
+
+			if claimed[candidate] {
+				continue
+			}
+
+			if id == 0 {
+				slugs[i] = candidate
+				break
+			}
+
+			if !owners[id] && service.hasTitle(id, post.Title) {
+				slugs[i] = candidate
+				adopted[i] = id
+				owners[id] = true
+				break
+			}
+		}
+		if slugs[i] != "" {
+			claimed[slugs[i]] = true
+		}
+	}
+
+	return slugs, adopted
+}
+
+func (service *ImportServiceImpl) hasTitle(articleID int64, title string) bool {
+	article, txErr := service.articleRepository.FindByID(articleID, &model.Selection{Fields: []string{"title"}})
+	util.ReturnErrorIfNeeded(txErr)
+
+	return article != nil && article.Title == title
+}
+
+func (service *ImportServiceImpl) importWXRPost(article *entity.Article, post importer.WXRPost, categories map[string]int64, source string, trashed bool, report *model.ImportReport, dryRun bool) (int64, error) {
+	if article.Title == "" {
+		return 0, errors.New("post has no title")
+	}
+
+	if utf8.RuneCountInString(article.Title) > maxArticleTitleLength {
+		return 0, errors.New("title is longer than 100 characters")
+	}
+
+	if article.Slug == "" || len(article.Slug) > maxArticleTitleLength {
+		return 0, errors.New("slug must be between 1 and 100 characters")
+	}
+
+	var categoryOK bool
+	for _, term := range post.Categories {
+		if article.CategoryID, categoryOK = categories[term.Nicename]; categoryOK {
+			break
+		}
+	}
+	if !categoryOK {
+		if len(post.Categories) > 0 {
+			return 0, errors.New("category " + post.Categories[0].Name + " could not be imported")
+		}
+
+		// Posts without any category go to the default category, which is
+		// resolved once and kept under the empty nicename.
+		if article.CategoryID, categoryOK = categories[""]; !categoryOK {
+			id, err := service.resolveCategory(wxrDefaultCategory, service.categoryIndex(), report, dryRun)
+			if err != nil {
+				return 0, err
+			}
+			categories[""] = id
+			article.CategoryID = id
+		}
+	}
+
+	if err := renderArticleContent(article, content.FormatHTML); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		return 0, nil
+	}
+
+	id, err := service.articleRepository.Insert(article)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := service.importRepository.SaveMapping(source, repository.ImportKindPost, strconv.FormatInt(post.ID, 10), id); err != nil {
+		return id, err
+	}
+
+	if trashed {
+		return id, service.articleRepository.SoftDelete(id)
+	}
+	return id, nil
+}
+
+// wxrStatus maps a WordPress post status to an article status. Scheduled,
+// pending and private posts become drafts; trashed posts are imported as
+// soft-deleted drafts.
+func wxrStatus(status string) (string, bool, error) {
+	switch status {
+	case "publish":
+		return entity.ArticleStatusPublished, false, nil
+	case "draft", "pending", "future", "private":
+		return entity.ArticleStatusDraft, false, nil
+	case "trash":
+		return entity.ArticleStatusDraft, true, nil
+	}
+	return "", false, errors.New("posts with status \"" + status + "\" are not imported")
+}
+
+func (service *ImportServiceImpl) importPost(post *importer.MarkdownPost, categories map[string]int64, report *model.ImportReport, dryRun bool) (int64, error) {
+	if utf8.RuneCountInString(post.Title) > maxArticleTitleLength {
+		return 0, errors.New("title is longer than 100 characters")
+	}
+
+	if post.Slug == "" || len(post.Slug) > maxArticleTitleLength {
+		return 0, errors.New("slug must be between 1 and 100 characters")
+	}
+
+	categoryID, err := service.resolveCategory(post.Category, categories, report, dryRun)
+	if err != nil {
+		return 0, err
+	}
+
+	status := entity.ArticleStatusPublished
+	if post.Draft {
+		status = entity.ArticleStatusDraft
+	}
+
+	article := entity.Article{
+		Title:       post.Title,
+		Slug:        post.Slug,
+		CategoryID:  categoryID,
+		Content:     post.Content,
+		Status:      status,
+		CreatedAt:   post.Date,
+		PublishedAt: post.Date,
+	}
+	if err := renderArticleContent(&article, content.FormatMarkdown); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		return 0, nil
+	}
+
+	return service.articleRepository.Insert(&article)
+}
+
+// resolveCategory matches categories by slug or case-insensitive name. In a
+// dry run a missing category is only recorded, with ID 0.
+func (service *ImportServiceImpl) resolveCategory(name string, categories map[string]int64, report *model.ImportReport, dryRun bool) (int64, error) {
+	key := slug.Make(name)
+	if id, ok := categories[key]; ok {
+		return id, nil
+	}
+
+	if id, ok := categories[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+
+	if utf8.RuneCountInString(name) > maxCategoryNameLength || len(key) > maxCategoryNameLength {
+		return 0, errors.New("category name is longer than 30 characters")
+	}
+
+	var id int64
+	if !dryRun {
+		id = service.categoryService.Create(&model.CategoryCreateRequest{CategoryName: name}).ID
+	}
+
+	categories[key] = id
+	categories[strings.ToLower(name)] = id
+	report.CategoriesCreated = append(report.CategoriesCreated, name)
+	return id, nil
+}
+
+func (service *ImportServiceImpl) categoryIndex() map[string]int64 {
+	index := map[string]int64{}
+	if categories := service.categoryService.List(); categories != nil {
+		for _, category := range *categories {
+			index[category.CategorySlug] = category.ID
+			index[strings.ToLower(category.CategoryName)] = category.ID
+		}
+	}
+	return index
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"io"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type MediaService interface {
+	Upload(filename string, contentType string, file io.ReadSeeker) *model.MediaResponse
+
+	FindOne(mediaID string) *model.MediaResponse
+
+	Open(mediaID string) (*model.MediaResponse, io.ReadSeekCloser)
+
+	OpenVariant(mediaID string, size string) (*model.MediaResponse, io.ReadSeekCloser)
+
+	ListByArticle(articleID string) *[]model.MediaResponse
+
+	Attach(articleID string, request *model.AttachmentRequest) *model.MediaResponse
+
+	Detach(articleID string, mediaID string)
+}
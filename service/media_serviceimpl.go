@@ -0,0 +1,229 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/imaging"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/storage"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const maxMediaFilenameLength = 255
+
+type MediaServiceImpl struct {
+	mediaRepository   repository.MediaRepository
+	articleRepository repository.ArticleRepository
+	blobs             storage.BlobStore
+	imageSizes        []imaging.Size
+	resizeSlots       chan struct{}
+}
+
+func NewMediaService(mediaRepo *repository.MediaRepository, articleRepo *repository.ArticleRepository, blobs storage.BlobStore, imageSizes []imaging.Size) MediaService {
+	return &MediaServiceImpl{
+		mediaRepository:   *mediaRepo,
+		articleRepository: *articleRepo,
+		blobs:             blobs,
+		imageSizes:        imageSizes,
+		resizeSlots:       make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// Upload stores the file under its SHA-256 and returns the existing media
+// item instead when the same content was uploaded before. The content type
+// must already have been sniffed by the caller.
+func (service *MediaServiceImpl) Upload(filename string, contentType string, file io.ReadSeeker) *model.MediaResponse {
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	util.ReturnErrorIfNeeded(err)
+
+	_, err = file.Seek(0, io.SeekStart)
+	util.ReturnErrorIfNeeded(err)
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	unlock := storage.LockKey(sum)
+	defer unlock()
+
+	existing, txErr := service.mediaRepository.FindBySHA256(sum)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if existing != nil {
+		return existing
+	}
+
+	var width, height int
+	if imaging.IsImage(contentType) {
+		width, height, err = imaging.Dimensions(file)
+		if err != nil {
+			panic(errors.New("image could not be decoded"))
+		}
+	}
+
+	err = service.blobs.Put(sum, file)
+	util.ReturnErrorIfNeeded(err)
+
+	filename = filepath.Base(filename)
+	if len(filename) > maxMediaFilenameLength || filename == "." || filename == string(filepath.Separator) {
+		filename = sum
+	}
+
+	mediaItem := entity.Media{
+		SHA256:      sum,
+		ContentType: contentType,
+		Size:        size,
+		Filename:    filename,
+		Width:       width,
+		Height:      height,
+	}
+	id, txErr := service.mediaRepository.Insert(&mediaItem)
+	util.ReturnErrorIfNeeded(txErr)
+
+	created, txErr := service.mediaRepository.FindByID(id)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return created
+}
+
+func (service *MediaServiceImpl) FindOne(mediaID string) *model.MediaResponse {
+	id, err := strconv.Atoi(mediaID)
+	util.ReturnErrorIfNeeded(err)
+
+	mediaItem, txErr := service.mediaRepository.FindByID(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return mediaItem
+}
+
+// Open returns nil for both values when the media item does not exist. The
+// caller must close the returned content.
+func (service *MediaServiceImpl) Open(mediaID string) (*model.MediaResponse, io.ReadSeekCloser) {
+	mediaItem := service.FindOne(mediaID)
+	if mediaItem == nil {
+		return nil, nil
+	}
+
+	content, err := service.blobs.Open(mediaItem.SHA256)
+	util.ReturnErrorIfNeeded(err)
+
+	return mediaItem, content
+}
+
+// OpenVariant returns an image resized to one of the configured sizes. The
+// variant is generated on first request and kept next to the original.
+func (service *MediaServiceImpl) OpenVariant(mediaID string, size string) (*model.MediaResponse, io.ReadSeekCloser) {
+	imageSize, ok := imaging.Find(service.imageSizes, size)
+	if !ok {
+		return nil, nil
+	}
+
+	original := service.FindOne(mediaID)
+	if original == nil || !imaging.IsImage(original.ContentType) {
+		return nil, nil
+	}
+
+	key := original.SHA256 + "-w" + strconv.Itoa(imageSize.Width)
+	content, err := service.blobs.Open(key)
+	if err == storage.ErrNotFound {
+		service.generateVariant(original.SHA256, key, imageSize.Width)
+		content, err = service.blobs.Open(key)
+	}
+	util.ReturnErrorIfNeeded(err)
+
+	variant := *original
+	variant.SHA256 = key
+	variant.Filename = size + "-" + original.Filename
+
+	variant.ContentType, err = storage.Sniff(content)
+	if err == nil {
+		variant.Size, err = content.Seek(0, io.SeekEnd)
+	}
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		content.Close()
+		panic(err)
+	}
+
+	return &variant, content
+}
+
+func (service *MediaServiceImpl) generateVariant(originalKey string, key string, width int) {
+	service.resizeSlots <- struct{}{}
+	defer func() { <-service.resizeSlots }()
+
+	original, err := service.blobs.Open(originalKey)
+	util.ReturnErrorIfNeeded(err)
+	defer original.Close()
+
+	data, _, err := imaging.Resize(original, width)
+	util.ReturnErrorIfNeeded(err)
+
+	err = service.blobs.Put(key, bytes.NewReader(data))
+	util.ReturnErrorIfNeeded(err)
+}
+
+func (service *MediaServiceImpl) ListByArticle(articleID string) *[]model.MediaResponse {
+	article := service.findLiveArticle(articleID)
+	if article == nil {
+		return nil
+	}
+
+	mediaItems, txErr := service.mediaRepository.FindAllByArticle(article.ID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return mediaItems
+}
+
+func (service *MediaServiceImpl) Attach(articleID string, request *model.AttachmentRequest) *model.MediaResponse {
+	article := service.findLiveArticle(articleID)
+	if article == nil {
+		return nil
+	}
+
+	mediaItem, txErr := service.mediaRepository.FindByID(request.MediaID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if mediaItem == nil {
+		panic(errors.New("media not found"))
+	}
+
+	txErr = service.mediaRepository.Attach(article.ID, mediaItem.ID)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return mediaItem
+}
+
+func (service *MediaServiceImpl) Detach(articleID string, mediaID string) {
+	articleIDInt, err := strconv.Atoi(articleID)
+	util.ReturnErrorIfNeeded(err)
+
+	mediaIDInt, err := strconv.Atoi(mediaID)
+	util.ReturnErrorIfNeeded(err)
+
+	txErr := service.mediaRepository.Detach(int64(articleIDInt), int64(mediaIDInt))
+	util.ReturnErrorIfNeeded(txErr)
+}
+
+func (service *MediaServiceImpl) findLiveArticle(articleID string) *model.ArticleResponse {
+	id, err := strconv.Atoi(articleID)
+	util.ReturnErrorIfNeeded(err)
+
+	article, txErr := service.articleRepository.FindByID(int64(id), nil)
+	util.ReturnErrorIfNeeded(txErr)
+
+	if article == nil || !article.DeletedAt.IsZero() {
+		return nil
+	}
+
+	return article
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"time"
+)
+
+type SitemapService interface {
+	ChangeListener
+
+	// Page returns sitemap page n for the base URL. Page 0 is /sitemap.xml,
+	// which becomes a sitemap index once there are too many URLs for one file.
+	// A nil page means it does not exist.
+	Page(baseURL string, n int) ([]byte, time.Time)
+}
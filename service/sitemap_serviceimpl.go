@@ -0,0 +1,129 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/sitemap"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+var sitemapSelection = model.Selection{
+	Fields: []string{"id", "created_at", "updated_at"},
+}
+
+type SitemapServiceImpl struct {
+	articleRepository  repository.ArticleRepository
+	categoryRepository repository.CategoryRepository
+
+	mu       sync.Mutex
+	baseURL  string
+	pages    [][]byte
+	modified time.Time
+}
+
+func NewSitemapService(articleRepo *repository.ArticleRepository, categoryRepo *repository.CategoryRepository) SitemapService {
+	return &SitemapServiceImpl{
+		articleRepository:  *articleRepo,
+		categoryRepository: *categoryRepo,
+	}
+}
+
+func (service *SitemapServiceImpl) OnChange(event model.ChangeEvent) {
+	if strings.HasPrefix(event.Type, "comment.") {
+		return
+	}
+
+	service.mu.Lock()
+	service.pages = nil
+	service.mu.Unlock()
+}
+
+func (service *SitemapServiceImpl) Page(baseURL string, n int) ([]byte, time.Time) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.pages == nil || service.baseURL != baseURL {
+		service.generate(baseURL)
+	}
+
+	if n < 0 || n >= len(service.pages) {
+		return nil, time.Time{}
+	}
+
+	return service.pages[n], service.modified
+}
+
+func (service *SitemapServiceImpl) generate(baseURL string) {
+	var urls []sitemap.URL
+	var modified time.Time
+
+	categories, txErr := service.categoryRepository.FindAll()
+	util.ReturnErrorIfNeeded(txErr)
+
+	for _, category := range *categories {
+		lastMod := lastModified(category.CreatedAt, category.UpdatedAt)
+		urls = append(urls, sitemap.URL{Loc: baseURL + "/category/slug/" + category.CategorySlug, LastMod: lastMod})
+		if lastMod.After(modified) {
+			modified = lastMod
+		}
+	}
+
+	articles, txErr := service.articleRepository.FindAllPublished(0, 0, &sitemapSelection)
+	util.ReturnErrorIfNeeded(txErr)
+
+	for _, article := range *articles {
+		lastMod := lastModified(article.CreatedAt, article.UpdatedAt)
+		urls = append(urls, sitemap.URL{Loc: baseURL + "/article/" + strconv.FormatInt(article.ID, 10), LastMod: lastMod})
+		if lastMod.After(modified) {
+			modified = lastMod
+		}
+	}
+
+	var pages [][]byte
+	if len(urls) <= sitemap.MaxURLs {
+		page, err := sitemap.URLSet(urls)
+		util.ReturnErrorIfNeeded(err)
+		pages = append(pages, page)
+	} else {
+		var children []sitemap.URL
+		pages = append(pages, nil)
+		for start := 0; start < len(urls); start += sitemap.MaxURLs {
+			end := start + sitemap.MaxURLs
+			if end > len(urls) {
+				end = len(urls)
+			}
+
+			page, err := sitemap.URLSet(urls[start:end])
+			util.ReturnErrorIfNeeded(err)
+			pages = append(pages, page)
+
+			childModified := time.Time{}
+			for _, url := range urls[start:end] {
+				if url.LastMod.After(childModified) {
+					childModified = url.LastMod
+				}
+			}
+			children = append(children, sitemap.URL{Loc: baseURL + "/sitemap-" + strconv.Itoa(len(pages)-1) + ".xml", LastMod: childModified})
+		}
+
+		index, err := sitemap.Index(children)
+		util.ReturnErrorIfNeeded(err)
+		pages[0] = index
+	}
+
+	service.baseURL = baseURL
+	service.pages = pages
+	service.modified = modified
+}
+
+func lastModified(createdAt time.Time, updatedAt time.Time) time.Time {
+	if updatedAt.After(createdAt) {
+		return updatedAt
+	}
+	return createdAt
+}
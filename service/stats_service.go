@@ -0,0 +1,9 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type StatsService interface {
+	Get(from string, to string) *model.StatsResponse
+}
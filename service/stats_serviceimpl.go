@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/util"
+)
+
+const (
+	statsDateLayout   = "2006-01-02"
+	statsDefaultRange = 30
+	statsMaxRange     = 366
+	statsTTL          = 10 * time.Second
+)
+
+type StatsServiceImpl struct {
+	statsRepository repository.StatsRepository
+
+	mu      sync.Mutex
+	entries map[string]statsEntry
+}
+
+type statsEntry struct {
+	stats     *model.StatsResponse
+	expiresAt time.Time
+}
+
+func NewStatsService(repo *repository.StatsRepository) StatsService {
+	return &StatsServiceImpl{
+		statsRepository: *repo,
+		entries:         map[string]statsEntry{},
+	}
+}
+
+// Get returns the dashboard statistics for the inclusive date range. Results
+// are kept for a few seconds so the endpoint can back every page render
+// without re-running the aggregations each time.
+func (service *StatsServiceImpl) Get(from string, to string) *model.StatsResponse {
+	start, end := statsRange(from, to)
+	key := start.Format(statsDateLayout) + "/" + end.Format(statsDateLayout)
+
+	service.mu.Lock()
+	entry, ok := service.entries[key]
+	service.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.stats
+	}
+
+	stats := service.compute(start, end)
+
+	service.mu.Lock()
+	for k, e := range service.entries {
+		if time.Now().After(e.expiresAt) {
+			delete(service.entries, k)
+		}
+	}
+	service.entries[key] = statsEntry{stats: stats, expiresAt: time.Now().Add(statsTTL)}
+	service.mu.Unlock()
+
+	return stats
+}
+
+func (service *StatsServiceImpl) compute(start time.Time, end time.Time) *model.StatsResponse {
+	totals, txErr := service.statsRepository.Totals()
+	util.ReturnErrorIfNeeded(txErr)
+
+	categories, txErr := service.statsRepository.ArticlesPerCategory()
+	util.ReturnErrorIfNeeded(txErr)
+
+	days, txErr := service.statsRepository.ArticlesCreatedPerDay(start, end)
+	util.ReturnErrorIfNeeded(txErr)
+
+	span := end.Sub(start)
+	previous, txErr := service.statsRepository.CountArticlesCreatedBetween(start.Add(-span), start)
+	util.ReturnErrorIfNeeded(txErr)
+
+	counts := map[string]int64{}
+	for _, day := range *days {
+		counts[day.Date] = day.Count
+	}
+
+	var current int64
+	daily := []model.DailyArticleCount{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(statsDateLayout)
+		daily = append(daily, model.DailyArticleCount{Date: date, Count: counts[date]})
+		current += counts[date]
+	}
+
+	growth := model.StatsGrowth{
+		From:     start,
+		To:       end.AddDate(0, 0, -1),
+		Current:  current,
+		Previous: previous,
+		Change:   current - previous,
+	}
+
+	if previous > 0 {
+		percent := float64(current-previous) / float64(previous) * 100
+		growth.ChangePercent = &percent
+	}
+
+	return &model.StatsResponse{
+		Totals:     *totals,
+		Categories: *categories,
+		Daily:      daily,
+		Growth:     growth,
+	}
+}
+
+// statsRange parses the inclusive from/to dates and returns a half-open
+// [start, end) range, defaulting to the last 30 days.
+func statsRange(from string, to string) (time.Time, time.Time) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if to != "" {
+		parsed, err := time.ParseInLocation(statsDateLayout, to, time.Local)
+		util.ReturnErrorIfNeeded(err)
+		end = parsed.AddDate(0, 0, 1)
+	}
+
+	start := end.AddDate(0, 0, -statsDefaultRange)
+	if from != "" {
+		parsed, err := time.ParseInLocation(statsDateLayout, from, time.Local)
+		util.ReturnErrorIfNeeded(err)
+		start = parsed
+	}
+
+	if !start.Before(end) {
+		panic(errors.New("stats range must start before it ends"))
+	}
+
+	if end.Sub(start) > statsMaxRange*24*time.Hour {
+		panic(errors.New("stats range must not exceed 366 days"))
+	}
+
+	return start, end
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/muhammadrijalkamal/backendtest/model"
+)
+
+type WebhookService interface {
+	ChangeListener
+
+	Create(request *model.WebhookCreateRequest) *model.WebhookResponse
+
+	List() *[]model.WebhookResponse
+
+	FindOne(webhookID string) *model.WebhookResponse
+
+	Update(webhookID string, request *model.WebhookUpdateRequest) *model.WebhookResponse
+
+	Delete(webhookID string)
+
+	ListDeliveries(webhookID string) *[]model.WebhookDeliveryResponse
+
+	FindDelivery(webhookID string, deliveryID string) *model.WebhookDeliveryResponse
+
+	Redeliver(webhookID string, deliveryID string) *model.WebhookDeliveryResponse
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muhammadrijalkamal/backendtest/entity"
+	"github.com/muhammadrijalkamal/backendtest/model"
+	"github.com/muhammadrijalkamal/backendtest/repository"
+	"github.com/muhammadrijalkamal/backendtest/util"
+	"github.com/muhammadrijalkamal/backendtest/webhook"
+)
+
+const (
+	webhookPollInterval     = 5 * time.Second
+	webhookSubscriptionsTTL = 30 * time.Second
+	webhookClaimBatch       = 50
+	webhookLease            = 2 * time.Minute
+	webhookConcurrency      = 8
+	webhookDeliveryHistory  = 100
+)
+
+type WebhookServiceImpl struct {
+	webhookRepository repository.WebhookRepository
+	client            *http.Client
+	wake              chan struct{}
+
+	mutex         sync.Mutex
+	subscriptions *[]model.WebhookResponse
+	loadedAt      time.Time
+}
+
+// NewWebhookService also starts the background worker that sends pending
+// deliveries and retries failed ones with exponential backoff.
+func NewWebhookService(webhookRepo *repository.WebhookRepository) WebhookService {
+	service := &WebhookServiceImpl{
+		webhookRepository: *webhookRepo,
+		client:            webhook.NewClient(10 * time.Second),
+		wake:              make(chan struct{}, 1),
+	}
+
+	go service.deliverLoop()
+	return service
+}
+
+func (service *WebhookServiceImpl) Create(request *model.WebhookCreateRequest) *model.WebhookResponse {
+	events, err := validateWebhook(request.URL, request.Events)
+	util.ReturnErrorIfNeeded(err)
+
+	secret := request.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	}
+
+	active := request.Active == nil || *request.Active
+	hook := entity.Webhook{
+		URL:    request.URL,
+		Secret: secret,
+		Events: strings.Join(events, ","),
+		Active: active,
+	}
+	id, txErr := service.webhookRepository.Insert(&hook)
+	util.ReturnErrorIfNeeded(txErr)
+	service.forgetSubscriptions()
+
+	created, txErr := service.webhookRepository.FindByID(id)
+	util.ReturnErrorIfNeeded(txErr)
+
+	// The secret is only ever shown in the response that created it.
+	return created
+}
+
+func (service *WebhookServiceImpl) List() *[]model.WebhookResponse {
+	hooks, txErr := service.webhookRepository.FindAll()
+	util.ReturnErrorIfNeeded(txErr)
+
+	for i := range *hooks {
+		(*hooks)[i].Secret = ""
+	}
+
+	return hooks
+}
+
+func (service *WebhookServiceImpl) FindOne(webhookID string) *model.WebhookResponse {
+	hook := service.findWebhook(webhookID)
+	if hook != nil {
+		hook.Secret = ""
+	}
+
+	return hook
+}
+
+func (service *WebhookServiceImpl) Update(webhookID string, request *model.WebhookUpdateRequest) *model.WebhookResponse {
+	existing := service.findWebhook(webhookID)
+	if existing == nil {
+		return nil
+	}
+
+	events, err := validateWebhook(request.URL, request.Events)
+	util.ReturnErrorIfNeeded(err)
+
+	hook := entity.Webhook{
+		URL:    request.URL,
+		Secret: existing.Secret,
+		Events: strings.Join(events, ","),
+		Active: existing.Active,
+	}
+	if request.Secret != "" {
+		hook.Secret = request.Secret
+	}
+	if request.Active != nil {
+		hook.Active = *request.Active
+	}
+
+	txErr := service.webhookRepository.Update(existing.ID, &hook)
+	util.ReturnErrorIfNeeded(txErr)
+	service.forgetSubscriptions()
+
+	return service.FindOne(webhookID)
+}
+
+func (service *WebhookServiceImpl) Delete(webhookID string) {
+	id, err := strconv.Atoi(webhookID)
+	util.ReturnErrorIfNeeded(err)
+
+	txErr := service.webhookRepository.Delete(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+	service.forgetSubscriptions()
+}
+
+func (service *WebhookServiceImpl) ListDeliveries(webhookID string) *[]model.WebhookDeliveryResponse {
+	hook := service.findWebhook(webhookID)
+	if hook == nil {
+		return nil
+	}
+
+	deliveries, txErr := service.webhookRepository.FindDeliveries(hook.ID, webhookDeliveryHistory)
+	util.ReturnErrorIfNeeded(txErr)
+
+	return deliveries
+}
+
+func (service *WebhookServiceImpl) FindDelivery(webhookID string, deliveryID string) *model.WebhookDeliveryResponse {
+	id, err := strconv.Atoi(webhookID)
+	util.ReturnErrorIfNeeded(err)
+
+	delivery, err := strconv.Atoi(deliveryID)
+	util.ReturnErrorIfNeeded(err)
+
+	found, txErr := service.webhookRepository.FindDelivery(int64(id), int64(delivery))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return found
+}
+
+func (service *WebhookServiceImpl) Redeliver(webhookID string, deliveryID string) *model.WebhookDeliveryResponse {
+	delivery := service.FindDelivery(webhookID, deliveryID)
+	if delivery == nil {
+		return nil
+	}
+
+	txErr := service.webhookRepository.Redeliver(delivery.WebhookID, delivery.ID)
+	util.ReturnErrorIfNeeded(txErr)
+	service.wakeUp()
+
+	return service.FindDelivery(webhookID, deliveryID)
+}
+
+// OnChange queues one delivery per subscribed webhook. The payload ID is the
+// outbox event ID, and a webhook never gets two deliveries for the same
+// event, so events the relay hands over twice are only sent once.
+func (service *WebhookServiceImpl) OnChange(event model.ChangeEvent) {
+	hooks, err := service.activeSubscriptions()
+	util.ReturnErrorIfNeeded(err)
+
+	var subscribers []model.WebhookResponse
+	for _, hook := range *hooks {
+		if model.MatchEventType(hook.Events, event.Type) {
+			subscribers = append(subscribers, hook)
+		}
+	}
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	payload := model.WebhookPayload{
+		ID:        event.ID,
+		Event:     event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Message().Data,
+	}
+	body, err := json.Marshal(payload)
+	util.ReturnErrorIfNeeded(err)
+
+	deliveries := make([]entity.WebhookDelivery, len(subscribers))
+	for i, hook := range subscribers {
+		deliveries[i] = entity.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventID:   payload.ID,
+			EventType: event.Type,
+			Payload:   string(body),
+		}
+	}
+
+	txErr := service.webhookRepository.InsertDeliveries(deliveries)
+	util.ReturnErrorIfNeeded(txErr)
+
+	service.wakeUp()
+}
+
+func (service *WebhookServiceImpl) activeSubscriptions() (*[]model.WebhookResponse, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.subscriptions != nil && time.Since(service.loadedAt) < webhookSubscriptionsTTL {
+		return service.subscriptions, nil
+	}
+
+	hooks, err := service.webhookRepository.FindAllActive()
+	if err != nil {
+		return nil, err
+	}
+
+	service.subscriptions = hooks
+	service.loadedAt = time.Now()
+	return hooks, nil
+}
+
+func (service *WebhookServiceImpl) forgetSubscriptions() {
+	service.mutex.Lock()
+	service.subscriptions = nil
+	service.mutex.Unlock()
+}
+
+func (service *WebhookServiceImpl) findWebhook(webhookID string) *model.WebhookResponse {
+	id, err := strconv.Atoi(webhookID)
+	util.ReturnErrorIfNeeded(err)
+
+	hook, txErr := service.webhookRepository.FindByID(int64(id))
+	util.ReturnErrorIfNeeded(txErr)
+
+	return hook
+}
+
+func (service *WebhookServiceImpl) wakeUp() {
+	select {
+	case service.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (service *WebhookServiceImpl) deliverLoop() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-service.wake:
+		case <-ticker.C:
+		}
+
+		service.deliverDue()
+	}
+}
+
+func (service *WebhookServiceImpl) deliverDue() {
+	for {
+		deliveries, err := service.webhookRepository.ClaimDueDeliveries(webhookClaimBatch, webhookLease)
+		if err != nil {
+			log.Printf("webhooks: claiming deliveries: %v", err)
+			return
+		}
+
+		if len(*deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, webhookConcurrency)
+		for _, delivery := range *deliveries {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(delivery model.WebhookDeliveryResponse) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				service.deliver(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+	}
+}
+
+func (service *WebhookServiceImpl) deliver(delivery model.WebhookDeliveryResponse) {
+	attempt := entity.WebhookDeliveryAttempt{DeliveryID: delivery.ID}
+
+	hook, err := service.webhookRepository.FindByID(delivery.WebhookID)
+	switch {
+	case err != nil:
+		attempt.Error = err.Error()
+	case hook == nil:
+		attempt.Error = "webhook no longer exists"
+	case !hook.Active:
+		attempt.Error = "webhook is inactive"
+	default:
+		result, sendErr := webhook.Send(service.client, hook.URL, hook.Secret, delivery.EventType, delivery.ID, delivery.Payload)
+		attempt.ResponseStatus = result.StatusCode
+		attempt.DurationMS = int64(result.Duration / time.Millisecond)
+		if sendErr != nil {
+			attempt.Error = sendErr.Error()
+		} else if !result.Succeeded() {
+			attempt.Error = fmt.Sprintf("unexpected status %d", result.StatusCode)
+		}
+	}
+
+	status := model.WebhookDeliverySucceeded
+	var retryIn time.Duration
+	if attempt.Error != "" {
+		status = model.WebhookDeliveryPending
+		retryIn = webhook.Backoff(delivery.Attempts + 1)
+		if delivery.Attempts+1 >= webhook.MaxAttempts {
+			status = model.WebhookDeliveryFailed
+		}
+	}
+
+	if err := service.webhookRepository.RecordAttempt(&attempt, status, retryIn); err != nil {
+		log.Printf("webhooks: recording attempt for delivery %d: %v", delivery.ID, err)
+	}
+}
+
+func validateWebhook(rawURL string, events []string) ([]string, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+
+	if ip := net.ParseIP(target.Hostname()); (ip != nil && webhook.IsInternal(ip)) || strings.EqualFold(target.Hostname(), "localhost") {
+		return nil, errors.New("url must not point to a loopback or private address")
+	}
+
+	var valid []string
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !model.ValidEventPattern(event) {
+			return nil, fmt.Errorf("unknown event %q", event)
+		}
+
+		if !util.Contains(valid, event) {
+			valid = append(valid, event)
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil, errors.New("events must not be empty")
+	}
+
+	return valid, nil
+}
+
+func newWebhookSecret() string {
+	var b [32]byte
+	_, err := rand.Read(b[:])
+	util.ReturnErrorIfNeeded(err)
+	return hex.EncodeToString(b[:])
+}
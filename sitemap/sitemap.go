@@ -0,0 +1,66 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the number of URLs a single sitemap may list according to the
+// sitemaps.org protocol.
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type URL struct {
+	Loc     string    `xml:"loc"`
+	LastMod time.Time `xml:"-"`
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []xmlEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	Sitemaps []xmlEntry `xml:"sitemap"`
+}
+
+type xmlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func URLSet(urls []URL) ([]byte, error) {
+	set := urlSet{XMLNS: namespace}
+	for _, url := range urls {
+		set.URLs = append(set.URLs, entry(url))
+	}
+	return marshal(set)
+}
+
+func Index(sitemaps []URL) ([]byte, error) {
+	index := sitemapIndex{XMLNS: namespace}
+	for _, sitemap := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, entry(sitemap))
+	}
+	return marshal(index)
+}
+
+func entry(url URL) xmlEntry {
+	e := xmlEntry{Loc: url.Loc}
+	if !url.LastMod.IsZero() {
+		e.LastMod = url.LastMod.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
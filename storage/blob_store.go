@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files by key. Keys are content hashes, so a blob
+// is never rewritten with different bytes. Files derived from a blob, such
+// as resized images, are stored under "<key>-<variant>" and deleted with it.
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+
+	Open(key string) (io.ReadSeekCloser, error)
+
+	Delete(key string) error
+}
+
+// LocalBlobStore stores blobs as files below Root, fanned out into two
+// levels of directories by the first characters of the key.
+type LocalBlobStore struct {
+	Root string
+}
+
+func NewLocalBlobStore(root string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStore{Root: root}, nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *LocalBlobStore) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalBlobStore) Open(key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	variants, err := filepath.Glob(path + "-*")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range append(variants, path) {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if len(key) < 4 || strings.ContainsAny(key, `/\.*?[`) {
+		return "", errors.New("invalid blob key " + key)
+	}
+	return filepath.Join(s.Root, key[0:2], key[2:4], key), nil
+}
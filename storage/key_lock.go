@@ -0,0 +1,41 @@
+package storage
+
+import "sync"
+
+var keyLocks = struct {
+	sync.Mutex
+	held map[string]*keyLock
+}{held: map[string]*keyLock{}}
+
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// LockKey serializes work on one blob key within this process and returns
+// the function that releases it. Uploads hold it from the lookup of the hash
+// until the media row is written, and garbage collection from the reference
+// check until the blob is deleted, so neither acts on the other's half-done
+// work.
+func LockKey(key string) func() {
+	keyLocks.Lock()
+	lock, ok := keyLocks.held[key]
+	if !ok {
+		lock = &keyLock{}
+		keyLocks.held[key] = lock
+	}
+	lock.waiters++
+	keyLocks.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		keyLocks.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(keyLocks.held, key)
+		}
+		keyLocks.Unlock()
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io"
+	"mime"
+	"net/http"
+)
+
+// AllowedContentTypes lists the sniffed types accepted for upload.
+var AllowedContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"audio/mpeg",
+	"video/mp4",
+	"video/webm",
+}
+
+// Sniff detects the content type from the first bytes of r, ignoring
+// whatever the client claimed, and rewinds r afterwards.
+func Sniff(r io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+func Allowed(contentType string) bool {
+	for _, allowed := range AllowedContentTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,52 @@
 package util
 
+import "errors"
+
 func ReturnErrorIfNeeded(err interface{}) {
 	if err != nil {
 		panic(err)
 	}
 }
+
+// ErrorKind says what was wrong with a request, so that each API can answer
+// with its own status without parsing messages.
+type ErrorKind int
+
+const (
+	ErrorInternal ErrorKind = iota
+	ErrorInvalid
+	ErrorNotFound
+	ErrorConflict
+)
+
+// Error is an error the client caused, as opposed to one of the database or
+// the server.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func InvalidError(message string) error {
+	return &Error{Kind: ErrorInvalid, Message: message}
+}
+
+func NotFoundError(message string) error {
+	return &Error{Kind: ErrorNotFound, Message: message}
+}
+
+func ConflictError(message string) error {
+	return &Error{Kind: ErrorConflict, Message: message}
+}
+
+// KindOf is the kind of the Error wrapped by err, or ErrorInternal.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return ErrorInternal
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	MaxAttempts = 10
+
+	maxResponseDrain = 1024
+)
+
+// Sign returns the X-Webhook-Signature value for a body sent at timestamp.
+// Receivers recompute HMAC-SHA256(secret, "<timestamp>.<body>") and should
+// reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is the delay before retrying after the given number of failed
+// attempts: one minute doubling up to six hours.
+func Backoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < 6*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+func (result Result) Succeeded() bool {
+	return result.StatusCode >= 200 && result.StatusCode < 300
+}
+
+// Send POSTs a signed payload. Only the status of the response is kept; the
+// body is never stored, since it is whatever the target chose to answer.
+func Send(client *http.Client, url string, secret string, eventType string, deliveryID int64, body []byte) (Result, error) {
+	var result Result
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "backendtest-webhooks/1")
+	request.Header.Set(HeaderEvent, eventType)
+	request.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	start := time.Now()
+	response, err := client.Do(request)
+	result.Duration = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	// A small body is read off so that the connection can be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, maxResponseDrain))
+	result.StatusCode = response.StatusCode
+	return result, nil
+}